@@ -54,6 +54,11 @@ func init() {
 	batteriesSessionsCmd.Flags().StringVarP(&batteriesDeviceID, "device", "d", "", "device ID")
 	batteriesSessionsCmd.Flags().StringVar(&batteriesStartDate, "start", "", "start date (YYYY-MM-DD)")
 	batteriesSessionsCmd.Flags().StringVar(&batteriesEndDate, "end", "", "end date (YYYY-MM-DD)")
+	for _, cmd := range []*cobra.Command{batteriesDetailsCmd, batteriesSessionsCmd} {
+		cobra.CheckErr(cmd.RegisterFlagCompletionFunc("device", completeBatteries))
+	}
+	bindDateFlag(batteriesSessionsCmd, batteriesSessionsCmd.Flags(), "start")
+	bindDateFlag(batteriesSessionsCmd, batteriesSessionsCmd.Flags(), "end")
 }
 
 func runBatteries(cmd *cobra.Command, args []string) error {
@@ -67,30 +72,24 @@ func runBatteriesList(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	resp, err := client.Execute(api.SmartBatteriesQuery, "SmartBatteries", nil)
+	batteries, err := fetchBatteries(client)
 	if err != nil {
 		if errors.Is(err, api.ErrSmartTradingNotEnabled) {
 			fmt.Println("Smart trading is not enabled for your account")
 			return nil
 		}
-		return fmt.Errorf("failed to fetch batteries: %w", err)
+		return err
 	}
 
-	var result models.SmartBatteriesResponse
-	if err := json.Unmarshal(resp.Data, &result); err != nil {
-		return fmt.Errorf("failed to parse response: %w", err)
+	if isStructuredOutput() {
+		return renderOutput(batteries, batteries)
 	}
 
-	batteries := result.SmartBatteries
 	if len(batteries) == 0 {
 		fmt.Println("No smart batteries found")
 		return nil
 	}
 
-	if getOutputFormat() == "json" {
-		return output.JSON(batteries)
-	}
-
 	headers := []string{"ID", "Brand", "Capacity", "Max Charge", "Max Discharge", "Provider"}
 	var rows [][]string
 
@@ -134,22 +133,13 @@ func runBatteriesDetails(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	variables := map[string]interface{}{
-		"deviceId": deviceID,
-	}
-
-	resp, err := client.Execute(api.SmartBatteryDetailsQuery, "SmartBattery", variables)
+	result, err := fetchBatteryDetails(client, deviceID)
 	if err != nil {
-		return fmt.Errorf("failed to fetch battery details: %w", err)
-	}
-
-	var result models.SmartBatteryDetailsResponse
-	if err := json.Unmarshal(resp.Data, &result); err != nil {
-		return fmt.Errorf("failed to parse response: %w", err)
+		return err
 	}
 
-	if getOutputFormat() == "json" {
-		return output.JSON(result)
+	if isStructuredOutput() {
+		return renderOutput(result, result)
 	}
 
 	if result.SmartBattery != nil {
@@ -206,30 +196,17 @@ func runBatteriesSessions(cmd *cobra.Command, args []string) error {
 		startDate = t.AddDate(0, 0, -30).Format("2006-01-02")
 	}
 
-	variables := map[string]interface{}{
-		"deviceId":  deviceID,
-		"startDate": startDate,
-		"endDate":   endDate,
-	}
-
-	resp, err := client.Execute(api.SmartBatterySessionsQuery, "SmartBatterySessions", variables)
+	sessions, err := fetchBatterySessions(client, deviceID, startDate, endDate)
 	if err != nil {
-		return fmt.Errorf("failed to fetch battery sessions: %w", err)
-	}
-
-	var result models.SmartBatterySessionsResponse
-	if err := json.Unmarshal(resp.Data, &result); err != nil {
-		return fmt.Errorf("failed to parse response: %w", err)
+		return err
 	}
-
-	sessions := result.SmartBatterySessions
 	if sessions == nil {
 		fmt.Println("No session data available")
 		return nil
 	}
 
-	if getOutputFormat() == "json" {
-		return output.JSON(sessions)
+	if isStructuredOutput() {
+		return renderOutput(sessions, batterySessionRecords(sessions))
 	}
 
 	fmt.Printf("Battery Sessions (%s to %s)\n", startDate, endDate)
@@ -257,19 +234,97 @@ func runBatteriesSessions(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func getFirstBatteryID(client *api.Client) (string, error) {
+func fetchBatteries(client *api.Client) ([]models.SmartBattery, error) {
 	resp, err := client.Execute(api.SmartBatteriesQuery, "SmartBatteries", nil)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("failed to fetch batteries: %w", err)
 	}
 
 	var result models.SmartBatteriesResponse
 	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result.SmartBatteries, nil
+}
+
+func fetchBatteryDetails(client *api.Client, deviceID string) (*models.SmartBatteryDetailsResponse, error) {
+	variables := map[string]interface{}{
+		"deviceId": deviceID,
+	}
+
+	resp, err := client.Execute(api.SmartBatteryDetailsQuery, "SmartBattery", variables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch battery details: %w", err)
+	}
+
+	var result models.SmartBatteryDetailsResponse
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &result, nil
+}
+
+func fetchBatterySessions(client *api.Client, deviceID, startDate, endDate string) (*models.SmartBatterySessions, error) {
+	variables := map[string]interface{}{
+		"deviceId":  deviceID,
+		"startDate": startDate,
+		"endDate":   endDate,
+	}
+
+	resp, err := client.Execute(api.SmartBatterySessionsQuery, "SmartBatterySessions", variables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch battery sessions: %w", err)
+	}
+
+	var result models.SmartBatterySessionsResponse
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result.SmartBatterySessions, nil
+}
+
+// BatterySessionRecord is a single battery session in CSV/TSV output
+type BatterySessionRecord struct {
+	DeviceID string `json:"deviceId"`
+	models.BatterySession
+}
+
+// InfluxPoint returns the battery session as a line protocol point
+func (r BatterySessionRecord) InfluxPoint() output.Point {
+	return output.Point{
+		Measurement: "battery_session",
+		Tags: []output.Tag{
+			{Key: "deviceId", Value: r.DeviceID},
+		},
+		Fields: []output.Field{
+			{Key: "result", Value: r.Result},
+			{Key: "cumulativeResult", Value: r.CumulativeResult},
+			{Key: "tradeIndex", Value: r.TradeIndex},
+			{Key: "status", Value: r.Status},
+		},
+		Time: parseTimestamp(r.Date),
+	}
+}
+
+func batterySessionRecords(sessions *models.SmartBatterySessions) []BatterySessionRecord {
+	records := make([]BatterySessionRecord, 0, len(sessions.Sessions))
+	for _, s := range sessions.Sessions {
+		records = append(records, BatterySessionRecord{DeviceID: sessions.DeviceID, BatterySession: s})
+	}
+	return records
+}
+
+func getFirstBatteryID(client *api.Client) (string, error) {
+	batteries, err := fetchBatteries(client)
+	if err != nil {
 		return "", err
 	}
 
-	if len(result.SmartBatteries) > 0 {
-		return result.SmartBatteries[0].ID, nil
+	if len(batteries) > 0 {
+		return batteries[0].ID, nil
 	}
 
 	return "", nil
@@ -0,0 +1,552 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/huh"
+	"github.com/spf13/cobra"
+
+	"github.com/pietern/frankie/internal/api"
+	"github.com/pietern/frankie/internal/models"
+	"github.com/pietern/frankie/internal/output"
+)
+
+var (
+	chargeSetID       string
+	chargeSetDeadline string
+	chargeSetMinLimit int
+	chargeSetMaxLimit int
+	chargeSetSmart    string
+	chargeSetSolar    string
+	chargeSetSchedule map[string]string
+	chargeSetDryRun   bool
+	chargeSetYes      bool
+)
+
+// chargeDevice is a smart vehicle or charger with its charge settings and state
+type chargeDevice struct {
+	ID             string
+	Name           string
+	CanSmartCharge bool
+	Settings       *models.ChargeSettings
+	State          *models.ChargeState
+	Information    *models.DeviceInfo
+	Interventions  []models.Intervention
+	IsReachable    bool
+	LastSeen       string
+
+	// Model and Records are the device as rendered in structured output
+	Model   interface{}
+	Records interface{}
+}
+
+// chargeDeviceKind describes how to fetch and change the charge settings of
+// vehicles or chargers
+type chargeDeviceKind struct {
+	// Name is the kind of device, as used in messages
+	Name  string
+	Fetch func(client *api.Client) ([]chargeDevice, error)
+
+	Mutation  string
+	Operation string
+	// Result is the field of the mutation result
+	Result string
+	// IDVariable is the mutation variable that holds the device ID
+	IDVariable string
+}
+
+var vehicleKind = chargeDeviceKind{
+	Name: "vehicle",
+	Fetch: func(client *api.Client) ([]chargeDevice, error) {
+		vehicles, err := fetchVehicles(client)
+		if err != nil {
+			return nil, err
+		}
+		var devices []chargeDevice
+		for _, v := range vehicles {
+			devices = append(devices, chargeDevice{
+				ID:             v.ID,
+				Name:           deviceName(v.Information),
+				CanSmartCharge: v.CanSmartCharge,
+				Settings:       v.ChargeSettings,
+				State:          v.ChargeState,
+				Information:    v.Information,
+				Interventions:  v.Interventions,
+				IsReachable:    v.IsReachable,
+				LastSeen:       v.LastSeen,
+				Model:          v,
+				Records:        vehicleRecords([]models.EnodeVehicle{v}),
+			})
+		}
+		return devices, nil
+	},
+	Mutation:   api.UpdateEnodeVehicleChargeSettingsMutation,
+	Operation:  "UpdateEnodeVehicleChargeSettings",
+	Result:     "updateEnodeVehicleChargeSettings",
+	IDVariable: "vehicleId",
+}
+
+var chargerKind = chargeDeviceKind{
+	Name: "charger",
+	Fetch: func(client *api.Client) ([]chargeDevice, error) {
+		chargers, err := fetchChargers(client)
+		if err != nil {
+			return nil, err
+		}
+		var devices []chargeDevice
+		for _, c := range chargers {
+			devices = append(devices, chargeDevice{
+				ID:             c.ID,
+				Name:           deviceName(c.Information),
+				CanSmartCharge: c.CanSmartCharge,
+				Settings:       c.ChargeSettings,
+				State:          c.ChargeState,
+				Information:    c.Information,
+				Interventions:  c.Interventions,
+				IsReachable:    c.IsReachable,
+				LastSeen:       c.LastSeen,
+				Model:          c,
+				Records:        chargerRecords([]models.EnodeCharger{c}),
+			})
+		}
+		return devices, nil
+	},
+	Mutation:   api.UpdateEnodeChargerChargeSettingsMutation,
+	Operation:  "UpdateEnodeChargerChargeSettings",
+	Result:     "updateEnodeChargerChargeSettings",
+	IDVariable: "chargerId",
+}
+
+// addChargeSettingsFlags adds the flags of 'vehicles set' and 'chargers set'
+func addChargeSettingsFlags(cmd *cobra.Command, kind string, completeID func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective)) {
+	cmd.Flags().StringVar(&chargeSetID, "id", "", fmt.Sprintf("ID of the %s (optional if you have one)", kind))
+	cmd.Flags().StringVar(&chargeSetDeadline, "deadline", "", "time charging should be done by (HH:MM)")
+	cmd.Flags().IntVar(&chargeSetMinLimit, "min-limit", 0, "charge level in percent that is charged right away")
+	cmd.Flags().IntVar(&chargeSetMaxLimit, "max-limit", 0, "charge level in percent to stop charging at")
+	cmd.Flags().StringVar(&chargeSetSmart, "smart", "", "smart charging: on or off")
+	cmd.Flags().StringVar(&chargeSetSolar, "solar", "", "solar charging: on or off")
+	cmd.Flags().StringToStringVar(&chargeSetSchedule, "schedule", nil, "deadline per weekday (HH:MM), e.g. weekdays=07:30,sat=10:00 (days: mon to sun, weekdays, weekend or daily)")
+	cmd.Flags().BoolVar(&chargeSetDryRun, "dry-run", false, "print the mutation instead of sending it")
+	cmd.Flags().BoolVarP(&chargeSetYes, "yes", "y", false, "change without asking for confirmation")
+	cobra.CheckErr(cmd.RegisterFlagCompletionFunc("id", completeID))
+	for _, name := range []string{"smart", "solar"} {
+		cobra.CheckErr(cmd.RegisterFlagCompletionFunc(name, cobra.FixedCompletions([]string{"on", "off"}, cobra.ShellCompDirectiveNoFileComp)))
+	}
+}
+
+// runChargeSettingsSet changes the charge settings of a vehicle or charger
+func runChargeSettingsSet(cmd *cobra.Command, kind chargeDeviceKind) error {
+	client, err := newAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+
+	devices, err := kind.Fetch(client)
+	if err != nil {
+		return err
+	}
+	device, err := selectChargeDevice(devices, chargeSetID, kind.Name, "with --id")
+	if err != nil {
+		return err
+	}
+	if device.Settings == nil {
+		return fmt.Errorf("%s %s has no charge settings", kind.Name, device.ID)
+	}
+
+	settings := *device.Settings
+	if err := applyChargeSettingsFlags(cmd, &settings); err != nil {
+		return err
+	}
+	if settings.IsSmartChargingEnabled && !device.Settings.IsSmartChargingEnabled && !device.CanSmartCharge {
+		return fmt.Errorf("%s %s does not support smart charging", kind.Name, device.ID)
+	}
+
+	changes := chargeSettingsChanges(device.Settings, &settings)
+	if len(changes) == 0 {
+		fmt.Fprintf(os.Stderr, "The charge settings of %s are unchanged\n", device.Name)
+		return nil
+	}
+
+	variables := map[string]interface{}{
+		kind.IDVariable:  device.ID,
+		"chargeSettings": chargeSettingsInput(&settings),
+	}
+
+	// The changes go to stderr when stdout is for the mutation or the result
+	w := os.Stdout
+	if chargeSetDryRun || isStructuredOutput() {
+		w = os.Stderr
+	}
+	fmt.Fprintf(w, "Changes to the charge settings of %s (%s):\n", device.Name, device.ID)
+	output.TableTo(w, []string{"Setting", "Current", "New"}, changes)
+
+	if chargeSetDryRun {
+		request := chargeSettingsRequest{
+			Query:         kind.Mutation,
+			OperationName: kind.Operation,
+			Variables:     variables,
+		}
+		if !isStructuredOutput() {
+			return output.JSON(request)
+		}
+		return renderOutput(request, request)
+	}
+
+	if err := checkChargeSettingsMutation(client, kind, variables); err != nil {
+		return err
+	}
+
+	if !chargeSetYes {
+		if !isTerminal(os.Stdin) {
+			return fmt.Errorf("refusing to change charge settings without confirmation (use --yes)")
+		}
+		confirmed := false
+		prompt := huh.NewConfirm().
+			Title("Apply these changes?").
+			Value(&confirmed)
+		if err := huh.NewForm(huh.NewGroup(prompt)).WithOutput(os.Stderr).Run(); err != nil {
+			return fmt.Errorf("prompt cancelled: %w", err)
+		}
+		if !confirmed {
+			return nil
+		}
+	}
+
+	resp, err := client.Execute(kind.Mutation, kind.Operation, variables)
+	if err != nil {
+		return fmt.Errorf("failed to update charge settings: %w", err)
+	}
+
+	if isStructuredOutput() {
+		var result map[string]*models.ChargeSettings
+		if err := json.Unmarshal(resp.Data, &result); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+		updated := result[kind.Result]
+		return renderOutput(updated, []*models.ChargeSettings{updated})
+	}
+
+	fmt.Printf("Updated the charge settings of %s\n", device.Name)
+	return nil
+}
+
+// chargeSettingsRequest is the GraphQL request printed by --dry-run
+type chargeSettingsRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// schemaType is a type reference in a GraphQL introspection result
+type schemaType struct {
+	Kind   string      `json:"kind"`
+	Name   string      `json:"name"`
+	OfType *schemaType `json:"ofType"`
+}
+
+// String formats the type as in a GraphQL document, such as String!
+func (t *schemaType) String() string {
+	switch {
+	case t == nil:
+		return ""
+	case t.Kind == "NON_NULL":
+		return t.OfType.String() + "!"
+	case t.Kind == "LIST":
+		return "[" + t.OfType.String() + "]"
+	}
+	return t.Name
+}
+
+// schemaValue is a field, argument or input field in an introspection result
+type schemaValue struct {
+	Name string      `json:"name"`
+	Type *schemaType `json:"type"`
+}
+
+// checkChargeSettingsMutation checks the schema of the API before the charge
+// settings are changed. The update mutations are not documented, so they are
+// only sent if the API has the mutation with the arguments and input fields
+// used here.
+func checkChargeSettingsMutation(client *api.Client, kind chargeDeviceKind, variables map[string]interface{}) error {
+	resp, err := client.Execute(api.ChargeSettingsSchemaQuery, "ChargeSettingsSchema", nil)
+	if err != nil {
+		return fmt.Errorf("failed to check the charge settings mutation: %w", err)
+	}
+
+	var result struct {
+		Schema struct {
+			MutationType *struct {
+				Fields []struct {
+					Name string        `json:"name"`
+					Args []schemaValue `json:"args"`
+				} `json:"fields"`
+			} `json:"mutationType"`
+		} `json:"__schema"`
+		Input *struct {
+			InputFields []schemaValue `json:"inputFields"`
+		} `json:"__type"`
+	}
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	unsupported := func(reason string, args ...interface{}) error {
+		return fmt.Errorf("the API does not support changing %s charge settings as expected (%s); nothing was changed", kind.Name, fmt.Sprintf(reason, args...))
+	}
+
+	var args []schemaValue
+	found := false
+	if result.Schema.MutationType != nil {
+		for _, field := range result.Schema.MutationType.Fields {
+			if field.Name == kind.Result {
+				args, found = field.Args, true
+			}
+		}
+	}
+	if !found {
+		return unsupported("no mutation %s", kind.Result)
+	}
+	want := map[string]string{
+		kind.IDVariable:  "String!",
+		"chargeSettings": chargeSettingsInputType + "!",
+	}
+	for _, arg := range args {
+		if t, ok := want[arg.Name]; ok && arg.Type.String() == t {
+			delete(want, arg.Name)
+		}
+	}
+	for name, t := range want {
+		return unsupported("no argument %s: %s", name, t)
+	}
+
+	if result.Input == nil {
+		return unsupported("no input type %s", chargeSettingsInputType)
+	}
+	fields := make(map[string]bool)
+	for _, f := range result.Input.InputFields {
+		fields[f.Name] = true
+	}
+	input, _ := variables["chargeSettings"].(map[string]interface{})
+	for name := range input {
+		if !fields[name] {
+			return unsupported("no input field %s", name)
+		}
+	}
+	return nil
+}
+
+// selectChargeDevice returns the device with the given ID, or the only device
+// if no ID is given. The hint tells how to give an ID.
+func selectChargeDevice(devices []chargeDevice, id, kind, hint string) (chargeDevice, error) {
+	if id != "" {
+		for _, d := range devices {
+			if d.ID == id {
+				return d, nil
+			}
+		}
+		return chargeDevice{}, fmt.Errorf("no %s with ID '%s'", kind, id)
+	}
+
+	switch len(devices) {
+	case 0:
+		return chargeDevice{}, fmt.Errorf("no smart %ss found", kind)
+	case 1:
+		return devices[0], nil
+	}
+	var labels []string
+	for _, d := range devices {
+		labels = append(labels, fmt.Sprintf("%s (%s)", d.ID, d.Name))
+	}
+	return chargeDevice{}, fmt.Errorf("you have %d %ss, select one %s: %s", len(devices), kind, hint, strings.Join(labels, "; "))
+}
+
+// deviceName returns the brand and model of a device
+func deviceName(info *models.DeviceInfo) string {
+	if info == nil {
+		return "unknown device"
+	}
+	return strings.TrimSpace(info.Brand + " " + info.Model)
+}
+
+// chargeWeekdays are the days of the charge schedule, from Monday
+var chargeWeekdays = []struct {
+	key  string
+	name string
+	// hour returns the deadline of the day, in minutes after midnight
+	hour func(s *models.ChargeSettings) *int
+}{
+	{"mon", "Monday", func(s *models.ChargeSettings) *int { return &s.HourMonday }},
+	{"tue", "Tuesday", func(s *models.ChargeSettings) *int { return &s.HourTuesday }},
+	{"wed", "Wednesday", func(s *models.ChargeSettings) *int { return &s.HourWednesday }},
+	{"thu", "Thursday", func(s *models.ChargeSettings) *int { return &s.HourThursday }},
+	{"fri", "Friday", func(s *models.ChargeSettings) *int { return &s.HourFriday }},
+	{"sat", "Saturday", func(s *models.ChargeSettings) *int { return &s.HourSaturday }},
+	{"sun", "Sunday", func(s *models.ChargeSettings) *int { return &s.HourSunday }},
+}
+
+// scheduleDays returns the indexes in chargeWeekdays of a day or group of days
+func scheduleDays(day string) ([]int, bool) {
+	switch strings.ToLower(day) {
+	case "daily":
+		return []int{0, 1, 2, 3, 4, 5, 6}, true
+	case "weekdays":
+		return []int{0, 1, 2, 3, 4}, true
+	case "weekend":
+		return []int{5, 6}, true
+	}
+	for i, d := range chargeWeekdays {
+		if strings.EqualFold(day, d.key) || strings.EqualFold(day, d.name) {
+			return []int{i}, true
+		}
+	}
+	return nil, false
+}
+
+// applyChargeSettingsFlags changes settings according to the flags that were given
+func applyChargeSettingsFlags(cmd *cobra.Command, settings *models.ChargeSettings) error {
+	flags := cmd.Flags()
+	changed := false
+	for _, name := range []string{"deadline", "min-limit", "max-limit", "smart", "solar", "schedule"} {
+		changed = changed || flags.Changed(name)
+	}
+	if !changed {
+		return fmt.Errorf("nothing to change (use --deadline, --schedule, --min-limit, --max-limit, --smart or --solar)")
+	}
+
+	if flags.Changed("deadline") {
+		minutes, err := parseClock(chargeSetDeadline)
+		if err != nil {
+			return fmt.Errorf("invalid deadline: %w", err)
+		}
+		settings.Deadline = formatClock(minutes)
+	}
+
+	// Groups of days are applied first, so single days can override them
+	days := make([]string, 0, len(chargeSetSchedule))
+	for day := range chargeSetSchedule {
+		days = append(days, day)
+	}
+	slices.SortFunc(days, func(a, b string) int {
+		indexesA, _ := scheduleDays(a)
+		indexesB, _ := scheduleDays(b)
+		return len(indexesB) - len(indexesA)
+	})
+	for _, day := range days {
+		indexes, ok := scheduleDays(day)
+		if !ok {
+			return fmt.Errorf("invalid schedule day '%s' (must be mon to sun, weekdays, weekend or daily)", day)
+		}
+		minutes, err := parseClock(chargeSetSchedule[day])
+		if err != nil {
+			return fmt.Errorf("invalid schedule for %s: %w", day, err)
+		}
+		for _, i := range indexes {
+			*chargeWeekdays[i].hour(settings) = minutes
+		}
+	}
+
+	if flags.Changed("min-limit") {
+		settings.MinChargeLimit = float64(chargeSetMinLimit)
+	}
+	if flags.Changed("max-limit") {
+		settings.MaxChargeLimit = float64(chargeSetMaxLimit)
+	}
+	for _, limit := range []float64{settings.MinChargeLimit, settings.MaxChargeLimit} {
+		if limit < 0 || limit > 100 {
+			return fmt.Errorf("invalid charge limit: %.0f%% (must be between 0%% and 100%%)", limit)
+		}
+	}
+	if settings.MinChargeLimit > settings.MaxChargeLimit {
+		return fmt.Errorf("minimum charge limit %.0f%% is above maximum charge limit %.0f%%", settings.MinChargeLimit, settings.MaxChargeLimit)
+	}
+
+	if flags.Changed("smart") {
+		on, err := parseSwitch(chargeSetSmart)
+		if err != nil {
+			return fmt.Errorf("invalid value for --smart: %w", err)
+		}
+		settings.IsSmartChargingEnabled = on
+	}
+	if flags.Changed("solar") {
+		on, err := parseSwitch(chargeSetSolar)
+		if err != nil {
+			return fmt.Errorf("invalid value for --solar: %w", err)
+		}
+		settings.IsSolarChargingEnabled = on
+	}
+	return nil
+}
+
+// chargeSettingsChanges returns the settings that differ, as rows of name, current and new value
+func chargeSettingsChanges(before, after *models.ChargeSettings) [][]string {
+	var rows [][]string
+	add := func(name, current, updated string) {
+		if current != updated {
+			rows = append(rows, []string{name, current, updated})
+		}
+	}
+	add("Deadline", before.Deadline, after.Deadline)
+	for _, d := range chargeWeekdays {
+		add(d.name, formatClock(*d.hour(before)), formatClock(*d.hour(after)))
+	}
+	add("Minimum charge limit", fmt.Sprintf("%.0f%%", before.MinChargeLimit), fmt.Sprintf("%.0f%%", after.MinChargeLimit))
+	add("Maximum charge limit", fmt.Sprintf("%.0f%%", before.MaxChargeLimit), fmt.Sprintf("%.0f%%", after.MaxChargeLimit))
+	add("Smart charging", formatSwitch(before.IsSmartChargingEnabled), formatSwitch(after.IsSmartChargingEnabled))
+	add("Solar charging", formatSwitch(before.IsSolarChargingEnabled), formatSwitch(after.IsSolarChargingEnabled))
+	return rows
+}
+
+// chargeSettingsInputType is the input type of the update mutations
+const chargeSettingsInputType = "EnodeChargeSettingsInput"
+
+// chargeSettingsInput returns the settings as input of the update mutations
+func chargeSettingsInput(s *models.ChargeSettings) map[string]interface{} {
+	input := map[string]interface{}{
+		"deadline":               s.Deadline,
+		"isSmartChargingEnabled": s.IsSmartChargingEnabled,
+		"isSolarChargingEnabled": s.IsSolarChargingEnabled,
+		"maxChargeLimit":         s.MaxChargeLimit,
+		"minChargeLimit":         s.MinChargeLimit,
+	}
+	for _, d := range chargeWeekdays {
+		input["hour"+d.name] = *d.hour(s)
+	}
+	return input
+}
+
+// parseClock parses a time of day (HH:MM) into minutes after midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a time (expected HH:MM)", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// formatClock formats minutes after midnight as HH:MM
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// parseSwitch parses on or off
+func parseSwitch(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "on", "true", "yes", "1":
+		return true, nil
+	case "off", "false", "no", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("'%s' is not on or off", value)
+}
+
+func formatSwitch(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pietern/frankie/internal/models"
+)
+
+func TestApplyChargeSettingsFlags(t *testing.T) {
+	current := models.ChargeSettings{
+		Deadline:       "07:00",
+		HourMonday:     420,
+		HourTuesday:    420,
+		HourWednesday:  420,
+		HourThursday:   420,
+		HourFriday:     420,
+		HourSaturday:   600,
+		HourSunday:     600,
+		MinChargeLimit: 20,
+		MaxChargeLimit: 80,
+	}
+	weekdays := func(s *models.ChargeSettings) []int {
+		var minutes []int
+		for _, d := range chargeWeekdays {
+			minutes = append(minutes, *d.hour(s))
+		}
+		return minutes
+	}
+
+	tests := []struct {
+		name     string
+		settings models.ChargeSettings
+		args     []string
+		want     []int
+		wantErr  string
+	}{
+		{
+			name:     "schedule",
+			settings: current,
+			args:     []string{"--schedule", "weekdays=07:30,fri=06:15"},
+			want:     []int{450, 450, 450, 450, 375, 600, 600},
+		},
+		{
+			// A device without a schedule has all deadlines at midnight
+			name: "schedule without one set",
+			args: []string{"--schedule", "daily=08:00,sun=10:00"},
+			want: []int{480, 480, 480, 480, 480, 480, 600},
+		},
+		{
+			name:     "other settings keep the schedule",
+			settings: current,
+			args:     []string{"--deadline", "06:45"},
+			want:     []int{420, 420, 420, 420, 420, 600, 600},
+		},
+		{name: "nothing", settings: current, wantErr: "nothing to change"},
+		{name: "invalid day", settings: current, args: []string{"--schedule", "someday=07:00"}, wantErr: "invalid schedule day 'someday'"},
+		{name: "invalid time", settings: current, args: []string{"--schedule", "mon=25:00"}, wantErr: "invalid schedule for mon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			addChargeSettingsFlags(cmd, "charger", nil)
+			if err := cmd.Flags().Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			settings := tt.settings
+			err := applyChargeSettingsFlags(cmd, &settings)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := weekdays(&settings); !slices.Equal(got, tt.want) {
+				t.Errorf("schedule = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
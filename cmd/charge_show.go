@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pietern/frankie/internal/api"
+	"github.com/pietern/frankie/internal/auth"
+	"github.com/pietern/frankie/internal/output"
+)
+
+var (
+	chargeShowWatch    bool
+	chargeShowInterval time.Duration
+)
+
+// addChargeShowFlags adds the flags of 'vehicles show' and 'chargers show'
+func addChargeShowFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&chargeShowWatch, "watch", "w", false, "refresh until interrupted")
+	cmd.Flags().DurationVar(&chargeShowInterval, "interval", 30*time.Second, "time between refreshes with --watch")
+}
+
+// completeFirstArg completes only the first argument with fn
+func completeFirstArg(fn func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective)) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return fn(cmd, args, toComplete)
+	}
+}
+
+// runChargeShow shows the details of a vehicle or charger, once or until interrupted
+func runChargeShow(cmd *cobra.Command, args []string, kind chargeDeviceKind) error {
+	if chargeShowWatch && isStructuredOutput() {
+		return fmt.Errorf("--watch cannot be combined with structured output")
+	}
+	if chargeShowInterval < 5*time.Second {
+		return fmt.Errorf("--interval must be at least 5s")
+	}
+
+	client, err := newAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+
+	if chargeShowWatch {
+		// Watching runs for a long time, so the token is renewed as needed
+		client.SetTokenSource(auth.NewManager(client).TokenSource())
+	}
+
+	id := ""
+	if len(args) > 0 {
+		id = args[0]
+	}
+
+	if !chargeShowWatch {
+		device, err := fetchChargeDevice(client, kind, id)
+		if err != nil {
+			if errors.Is(err, api.ErrSmartChargingNotEnabled) {
+				fmt.Println("Smart charging is not enabled for your account")
+				return nil
+			}
+			return err
+		}
+		if isStructuredOutput() {
+			return renderOutput(device.Model, device.Records)
+		}
+		renderChargeDevice(os.Stdout, device)
+		return nil
+	}
+
+	ctx, stop := signalContext(cmd)
+	defer stop()
+
+	// Redraw in place on a terminal, and append otherwise
+	redraw := isTerminal(os.Stdout)
+	for first := true; ; first = false {
+		var b strings.Builder
+		device, err := fetchChargeDevice(client, kind, id)
+		if err != nil {
+			fmt.Fprintf(&b, "Error: %v\n", err)
+		} else {
+			renderChargeDevice(&b, device)
+		}
+		fmt.Fprintf(&b, "\nUpdated %s, refreshing every %s (press Ctrl+C to stop)\n",
+			time.Now().Format("15:04:05"), chargeShowInterval)
+
+		if redraw {
+			fmt.Print("\033[H\033[2J")
+		} else if !first {
+			fmt.Println()
+		}
+		fmt.Print(b.String())
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(chargeShowInterval):
+		}
+	}
+}
+
+// fetchChargeDevice fetches the vehicle or charger with the given ID, or the only one
+func fetchChargeDevice(client *api.Client, kind chargeDeviceKind, id string) (chargeDevice, error) {
+	devices, err := kind.Fetch(client)
+	if err != nil {
+		return chargeDevice{}, err
+	}
+	return selectChargeDevice(devices, id, kind.Name, "by its ID")
+}
+
+// renderChargeDevice writes the status, charge state, charge settings and
+// interventions of a device
+func renderChargeDevice(w io.Writer, d chargeDevice) {
+	fmt.Fprintf(w, "%s (%s)\n\n", d.Name, d.ID)
+
+	status := "Offline"
+	if d.IsReachable {
+		status = "Online"
+	}
+	smart := "Not supported"
+	if d.CanSmartCharge {
+		smart = "Supported"
+	}
+	keys := []string{"Year", "VIN", "Status", "Last Seen", "Smart Charging"}
+	pairs := map[string]string{
+		"Status":         status,
+		"Smart Charging": smart,
+	}
+	if d.Information != nil {
+		if d.Information.Year > 0 {
+			pairs["Year"] = fmt.Sprint(d.Information.Year)
+		}
+		if d.Information.VIN != "" {
+			pairs["VIN"] = d.Information.VIN
+		}
+	}
+	if t := parseTimestamp(d.LastSeen); !t.IsZero() {
+		pairs["Last Seen"] = fmt.Sprintf("%s (%s)", t.Local().Format("2006-01-02 15:04"), formatTimeSince(t))
+	}
+	output.KeyValueOrderedTo(w, keys, pairs)
+
+	if s := d.State; s != nil {
+		fmt.Fprintln(w, "\nCharge state")
+		keys := []string{"Battery", "Range", "Charge Limit", "Plugged In", "Charging", "Time Remaining", "Power Delivery", "Last Updated"}
+		pairs := map[string]string{
+			"Plugged In": yesNo(s.IsPluggedIn),
+			"Charging":   yesNo(s.IsCharging),
+		}
+		if s.BatteryLevel > 0 {
+			pairs["Battery"] = fmt.Sprintf("%.0f%%", s.BatteryLevel)
+			if s.BatteryCapacity > 0 {
+				pairs["Battery"] += fmt.Sprintf(" of %.1f kWh", s.BatteryCapacity)
+			}
+		}
+		if s.IsFullyCharged {
+			pairs["Battery"] = strings.TrimSpace(pairs["Battery"] + " (fully charged)")
+		}
+		if s.Range > 0 {
+			pairs["Range"] = fmt.Sprintf("%.0f km", s.Range)
+		}
+		if s.ChargeLimit > 0 {
+			pairs["Charge Limit"] = fmt.Sprintf("%.0f%%", s.ChargeLimit)
+		}
+		if s.IsCharging && s.ChargeRate > 0 {
+			pairs["Charging"] = fmt.Sprintf("Yes, at %.1f kW", s.ChargeRate)
+		}
+		if s.IsCharging && s.ChargeTimeRemaining > 0 {
+			pairs["Time Remaining"] = formatMinutes(s.ChargeTimeRemaining)
+		}
+		if s.PowerDeliveryState != "" {
+			pairs["Power Delivery"] = s.PowerDeliveryState
+		}
+		if t := parseTimestamp(s.LastUpdated); !t.IsZero() {
+			pairs["Last Updated"] = fmt.Sprintf("%s (%s)", t.Local().Format("2006-01-02 15:04"), formatTimeSince(t))
+		}
+		output.KeyValueOrderedTo(w, keys, pairs)
+	}
+
+	if s := d.Settings; s != nil {
+		fmt.Fprintln(w, "\nCharge settings")
+		keys := []string{"Smart Charging", "Solar Charging", "Deadline", "Next Deadline", "Min Charge Limit", "Max Charge Limit", "Capacity", "Initial Charge"}
+		pairs := map[string]string{
+			"Smart Charging":   formatSwitch(s.IsSmartChargingEnabled),
+			"Solar Charging":   formatSwitch(s.IsSolarChargingEnabled),
+			"Min Charge Limit": fmt.Sprintf("%.0f%%", s.MinChargeLimit),
+			"Max Charge Limit": fmt.Sprintf("%.0f%%", s.MaxChargeLimit),
+		}
+		if s.Deadline != "" {
+			pairs["Deadline"] = s.Deadline
+		}
+		if t := parseTimestamp(s.CalculatedDeadline); !t.IsZero() {
+			pairs["Next Deadline"] = t.Local().Format("Monday 2006-01-02 15:04")
+		}
+		if s.Capacity > 0 {
+			pairs["Capacity"] = fmt.Sprintf("%g kW", s.Capacity)
+		}
+		if t := parseTimestamp(s.InitialChargeTimestamp); !t.IsZero() {
+			pairs["Initial Charge"] = fmt.Sprintf("%.0f%% at %s", s.InitialCharge, t.Local().Format("2006-01-02 15:04"))
+		}
+		output.KeyValueOrderedTo(w, keys, pairs)
+
+		fmt.Fprintln(w, "\nSchedule")
+		var days []string
+		schedule := map[string]string{}
+		for _, day := range chargeWeekdays {
+			days = append(days, day.name)
+			schedule[day.name] = formatClock(*day.hour(s))
+		}
+		output.KeyValueOrderedTo(w, days, schedule)
+	}
+
+	fmt.Fprintln(w, "\nInterventions")
+	if len(d.Interventions) == 0 {
+		fmt.Fprintln(w, "None, no action required")
+	}
+	for _, i := range d.Interventions {
+		if i.Description != "" {
+			fmt.Fprintf(w, "- %s: %s\n", i.Title, i.Description)
+		} else {
+			fmt.Fprintf(w, "- %s\n", i.Title)
+		}
+	}
+}
+
+// yesNo formats a boolean as Yes or No
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
+
+// formatMinutes formats a number of minutes as hours and minutes
+func formatMinutes(minutes float64) string {
+	m := int(minutes + 0.5)
+	if m < 60 {
+		return fmt.Sprintf("%dm", m)
+	}
+	return fmt.Sprintf("%dh %02dm", m/60, m%60)
+}
+
+// formatTimeSince formats the time elapsed since t
+func formatTimeSince(t time.Time) string {
+	elapsed := time.Since(t)
+
+	if elapsed < time.Minute {
+		return "just now"
+	}
+
+	hours := elapsed.Hours()
+	if hours >= 24 {
+		days := int(hours / 24)
+		if days == 1 {
+			return "1 day ago"
+		}
+		return fmt.Sprintf("%d days ago", days)
+	}
+
+	if hours >= 1 {
+		if int(hours) == 1 {
+			return "1 hour ago"
+		}
+		return fmt.Sprintf("%d hours ago", int(hours))
+	}
+
+	minutes := int(elapsed.Minutes())
+	if minutes == 1 {
+		return "1 minute ago"
+	}
+	return fmt.Sprintf("%d minutes ago", minutes)
+}
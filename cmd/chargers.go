@@ -19,8 +19,50 @@ var chargersCmd = &cobra.Command{
 	RunE:  runChargers,
 }
 
+var chargersSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Change the smart charging settings of a charger",
+	Long: `Change the smart charging settings of a charger: the deadline, the deadline
+per weekday, the charge limits and whether smart and solar charging are on.
+Settings that are not given are kept.
+
+The changes are shown and must be confirmed, unless --yes is given. With
+--dry-run, the GraphQL mutation is printed instead of sent.
+
+Examples:
+  frankie chargers set --smart on --schedule weekdays=07:00,weekend=09:00
+  frankie chargers set --id ID --schedule mon=07:00,sat=10:00 --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runChargeSettingsSet(cmd, chargerKind)
+	},
+}
+
+var chargersShowCmd = &cobra.Command{
+	Use:   "show [id]",
+	Short: "Show the details of a charger",
+	Long: `Show the status, charge state, smart charging settings and interventions
+of a charger, along with how long ago it was last seen. The ID is optional if
+you have one charger.
+
+With --watch, the details are refreshed until interrupted.
+
+Examples:
+  frankie chargers show
+  frankie chargers show ID --watch --interval 1m`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeFirstArg(completeChargers),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runChargeShow(cmd, args, chargerKind)
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(chargersCmd)
+	chargersCmd.AddCommand(chargersShowCmd)
+	chargersCmd.AddCommand(chargersSetCmd)
+	addChargeShowFlags(chargersShowCmd)
+	addChargeSettingsFlags(chargersSetCmd, "charger", completeChargers)
 }
 
 func runChargers(cmd *cobra.Command, args []string) error {
@@ -29,30 +71,24 @@ func runChargers(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	resp, err := client.Execute(api.EnodeChargersQuery, "EnodeChargers", nil)
+	chargers, err := fetchChargers(client)
 	if err != nil {
 		if errors.Is(err, api.ErrSmartChargingNotEnabled) {
 			fmt.Println("Smart charging is not enabled for your account")
 			return nil
 		}
-		return fmt.Errorf("failed to fetch chargers: %w", err)
+		return err
 	}
 
-	var result models.EnodeChargersResponse
-	if err := json.Unmarshal(resp.Data, &result); err != nil {
-		return fmt.Errorf("failed to parse response: %w", err)
+	if isStructuredOutput() {
+		return renderOutput(chargers, chargerRecords(chargers))
 	}
 
-	chargers := result.EnodeChargers
 	if len(chargers) == 0 {
 		fmt.Println("No smart chargers found")
 		return nil
 	}
 
-	if getOutputFormat() == "json" {
-		return output.JSON(chargers)
-	}
-
 	headers := []string{"Brand", "Model", "Status", "Smart", "Plugged In", "Charging", "Rate"}
 	var rows [][]string
 
@@ -98,3 +134,71 @@ func runChargers(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func fetchChargers(client *api.Client) ([]models.EnodeCharger, error) {
+	resp, err := client.Execute(api.EnodeChargersQuery, "EnodeChargers", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch chargers: %w", err)
+	}
+
+	var result models.EnodeChargersResponse
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result.EnodeChargers, nil
+}
+
+// ChargerRecord is a smart charger in CSV/TSV and line protocol output
+type ChargerRecord struct {
+	models.EnodeCharger
+}
+
+// InfluxPoint returns the charge state of the charger as a line protocol point
+func (r ChargerRecord) InfluxPoint() output.Point {
+	return chargeStatePoint("charger", r.ID, r.Information, r.ChargeState, r.IsReachable)
+}
+
+func chargerRecords(chargers []models.EnodeCharger) []ChargerRecord {
+	records := make([]ChargerRecord, 0, len(chargers))
+	for _, c := range chargers {
+		records = append(records, ChargerRecord{EnodeCharger: c})
+	}
+	return records
+}
+
+// chargeStatePoint returns the charge state of a charger or vehicle as a line protocol point
+func chargeStatePoint(kind, id string, info *models.DeviceInfo, state *models.ChargeState, reachable bool) output.Point {
+	point := output.Point{
+		Measurement: "charge_state",
+		Tags: []output.Tag{
+			{Key: "kind", Value: kind},
+			{Key: "deviceId", Value: id},
+		},
+		Fields: []output.Field{
+			{Key: "isReachable", Value: reachable},
+		},
+	}
+	if info != nil {
+		point.Tags = append(point.Tags,
+			output.Tag{Key: "brand", Value: info.Brand},
+			output.Tag{Key: "model", Value: info.Model},
+		)
+	}
+	if state != nil {
+		point.Fields = append(point.Fields,
+			output.Field{Key: "batteryLevel", Value: state.BatteryLevel},
+			output.Field{Key: "batteryCapacity", Value: state.BatteryCapacity},
+			output.Field{Key: "chargeLimit", Value: state.ChargeLimit},
+			output.Field{Key: "chargeRate", Value: state.ChargeRate},
+			output.Field{Key: "chargeTimeRemaining", Value: state.ChargeTimeRemaining},
+			output.Field{Key: "range", Value: state.Range},
+			output.Field{Key: "isCharging", Value: state.IsCharging},
+			output.Field{Key: "isPluggedIn", Value: state.IsPluggedIn},
+			output.Field{Key: "isFullyCharged", Value: state.IsFullyCharged},
+			output.Field{Key: "powerDeliveryState", Value: state.PowerDeliveryState},
+		)
+		point.Time = parseTimestamp(state.LastUpdated)
+	}
+	return point
+}
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/pietern/frankie/internal/api"
+	"github.com/pietern/frankie/internal/auth"
+	"github.com/pietern/frankie/internal/cache"
+	"github.com/pietern/frankie/internal/config"
+	"github.com/pietern/frankie/internal/models"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion bash|zsh|fish|powershell",
+	Short: "Generate a shell completion script",
+	Long: `Generate a completion script for bash, zsh, fish or PowerShell.
+
+Besides commands and flags, the script completes site references, device
+IDs and dates (such as today and tomorrow). Sites and devices are fetched
+from the API and cached for an hour, so completion stays fast; set
+completion_cache_ttl to change this. 'frankie logout' clears the cache.
+
+Examples:
+  # Bash, for the current shell and for new shells
+  source <(frankie completion bash)
+  frankie completion bash > ~/.local/share/bash-completion/completions/frankie
+
+  # Zsh, with compinit enabled
+  frankie completion zsh > "${fpath[1]}/_frankie"
+
+  # Fish
+  frankie completion fish > ~/.config/fish/completions/frankie.fish`,
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	DisableFlagsInUseLine: true,
+	// Generating the script needs neither settings nor credentials
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	RunE:              runCompletion,
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
+
+func runCompletion(cmd *cobra.Command, args []string) error {
+	switch args[0] {
+	case "bash":
+		return rootCmd.GenBashCompletionV2(os.Stdout, true)
+	case "zsh":
+		return rootCmd.GenZshCompletion(os.Stdout)
+	case "fish":
+		return rootCmd.GenFishCompletion(os.Stdout, true)
+	case "powershell":
+		return rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+	}
+	return fmt.Errorf("unsupported shell '%s'", args[0])
+}
+
+// completionCacheDir returns where responses for shell completion are cached
+func completionCacheDir(profile string) string {
+	return filepath.Join(config.GetProfileCacheDir(profile), "completion")
+}
+
+// completionClient creates an API client for shell completion. Completion
+// runs without the root command's setup and must never prompt, so the
+// profile is selected here and credentials are only read when a response
+// is not cached.
+func completionClient() (*api.Client, error) {
+	if err := config.CheckDirs(); err != nil {
+		return nil, err
+	}
+	config.SetProfile(profileName)
+	profile := config.GetProfile()
+	if err := config.ValidateProfileName(profile); err != nil {
+		return nil, err
+	}
+	auth.PromptPassphrase = nil
+
+	client := api.NewClient()
+	client.SetTokenSource(auth.NewManager(client).TokenSource())
+	ttl := time.Hour
+	if value, err := config.GetSetting(config.SettingCompletionTTL); err == nil {
+		if d, err := time.ParseDuration(value.Value); err == nil {
+			ttl = d
+		}
+	}
+	if ttl > 0 {
+		client.SetCache(cache.NewFile(completionCacheDir(profile)), ttl)
+	}
+	return client, nil
+}
+
+// completeSites completes site references, described by their address
+func completeSites(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	client, err := completionClient()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	sites, err := fetchSites(client)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, site := range (siteFilter{}).apply(sites) {
+		completion := site.Reference
+		if site.Address != nil && site.Address.FormattedAddress() != "" {
+			completion += "\t" + site.Address.FormattedAddress()
+		}
+		completions = append(completions, completion)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSiteArg completes a site reference as the only argument
+func completeSiteArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeSites(cmd, args, toComplete)
+}
+
+// completeBatteries completes smart battery IDs, described by brand and capacity
+func completeBatteries(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	client, err := completionClient()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	batteries, err := fetchBatteries(client)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, b := range batteries {
+		completions = append(completions, fmt.Sprintf("%s\t%s %.1f kWh", b.ID, b.Brand, b.Capacity))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeVehicles completes smart vehicle IDs, described by brand and model
+func completeVehicles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	client, err := completionClient()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	vehicles, err := fetchVehicles(client)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, v := range vehicles {
+		completions = append(completions, deviceCompletion(v.ID, v.Information))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeChargers completes smart charger IDs, described by brand and model
+func completeChargers(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	client, err := completionClient()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	chargers, err := fetchChargers(client)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, c := range chargers {
+		completions = append(completions, deviceCompletion(c.ID, c.Information))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// deviceCompletion returns a completion for a device ID with its brand and model
+func deviceCompletion(id string, info *models.DeviceInfo) string {
+	if info == nil {
+		return id
+	}
+	description := strings.TrimSpace(info.Brand + " " + info.Model)
+	if description == "" {
+		return id
+	}
+	return id + "\t" + description
+}
+
+// completeDates completes relative dates and the dates around today
+func completeDates(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var completions []string
+	for _, name := range relativeDateNames {
+		date, _ := resolveRelativeDate(name)
+		completions = append(completions, name+"\t"+date)
+	}
+	// Typing a digit switches to dates, from a week ago until tomorrow
+	if toComplete != "" && toComplete[0] >= '0' && toComplete[0] <= '9' {
+		completions = nil
+		now := time.Now()
+		for days := 1; days >= -7; days-- {
+			date := now.AddDate(0, 0, days)
+			completions = append(completions, date.Format("2006-01-02")+"\t"+date.Format("Monday"))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// dateFlags are flags that accept relative dates such as today and tomorrow
+var dateFlags = map[*pflag.Flag]bool{}
+
+// bindDateFlag makes a flag accept relative dates and completes dates for it
+func bindDateFlag(cmd *cobra.Command, flags *pflag.FlagSet, name string) {
+	dateFlags[flags.Lookup(name)] = true
+	cobra.CheckErr(cmd.RegisterFlagCompletionFunc(name, completeDates))
+}
+
+// bindSiteFlag completes site references for a flag
+func bindSiteFlag(cmd *cobra.Command, name string) {
+	cobra.CheckErr(cmd.RegisterFlagCompletionFunc(name, completeSites))
+}
+
+// resolveDateFlags replaces relative dates given to date flags with the date they refer to
+func resolveDateFlags(cmd *cobra.Command) error {
+	var err error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if !dateFlags[f] || !f.Changed || err != nil {
+			return
+		}
+		date, ok := resolveRelativeDate(f.Value.String())
+		if !ok {
+			return
+		}
+		if setErr := f.Value.Set(date); setErr != nil {
+			err = fmt.Errorf("invalid value for --%s: %w", f.Name, setErr)
+		}
+	})
+	return err
+}
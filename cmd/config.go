@@ -0,0 +1,363 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/pietern/frankie/internal/auth"
+	"github.com/pietern/frankie/internal/config"
+	"github.com/pietern/frankie/internal/output"
+)
+
+var configGlobal bool
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage settings",
+	Long: `Manage settings such as the default site, country, price resolution and
+output format.
+
+Settings are kept in config.yaml in the config directory, and profiles other
+than "default" can override them in their own config.yaml. A value is taken
+from the command-line flag, then the environment variable of the setting,
+then the profile settings, then the global settings, and is the default
+otherwise. 'frankie config list' shows where each value comes from.
+
+Examples:
+  frankie config set site 1234AB
+  frankie config set output json --global
+  frankie config get resolution
+  frankie config edit`,
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List settings with their values and sources",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigList,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:       "get <key>",
+	Short:     "Print the value of a setting",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: settingNames(),
+	RunE:      runConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:       "set <key> <value>",
+	Short:     "Change a setting",
+	Args:      cobra.ExactArgs(2),
+	ValidArgs: settingNames(),
+	RunE:      runConfigSet,
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:       "unset <key>",
+	Short:     "Remove a setting",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: settingNames(),
+	RunE:      runConfigUnset,
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit the settings file in $VISUAL or $EDITOR",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigEdit,
+}
+
+var configPathsCmd = &cobra.Command{
+	Use:   "paths",
+	Short: "Show where files are kept",
+	Long: `Show where settings, credentials, the history database, cached responses
+and logs of the active profile are kept.
+
+Settings and credentials are kept in $XDG_CONFIG_HOME/frankie, the history
+database in $XDG_DATA_HOME/frankie, cached responses in $XDG_CACHE_HOME/frankie
+and logs in $XDG_STATE_HOME/frankie. These default to ~/.config, ~/.local/share,
+~/.cache and ~/.local/state. If $FRANKIE_HOME is set, all files are kept in its
+config, data, cache and state subdirectories instead.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigPaths,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configPathsCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configEditCmd)
+	for _, cmd := range []*cobra.Command{configSetCmd, configUnsetCmd, configEditCmd} {
+		cmd.Flags().BoolVar(&configGlobal, "global", false, "change the global settings instead of those of the active profile")
+	}
+}
+
+// settingBinding links a command-line flag to the setting that provides its default
+type settingBinding struct {
+	key string
+	// convert maps the setting value to a flag value, if they differ
+	convert func(string) string
+}
+
+var flagSettings = map[*pflag.Flag]settingBinding{}
+
+// bindSetting makes a setting the default of a flag
+func bindSetting(flags *pflag.FlagSet, name, key string) {
+	flagSettings[flags.Lookup(name)] = settingBinding{key: key}
+}
+
+// bindCountrySetting makes the country setting the default of the --be flag
+func bindCountrySetting(flags *pflag.FlagSet, name string) {
+	flagSettings[flags.Lookup(name)] = settingBinding{
+		key:     config.SettingCountry,
+		convert: func(country string) string { return fmt.Sprint(country == "BE") },
+	}
+}
+
+// applySettings sets flags that were not given on the command line from
+// their settings, and applies the timezone setting
+func applySettings(cmd *cobra.Command) error {
+	var err error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		binding, ok := flagSettings[f]
+		if !ok || f.Changed || err != nil {
+			return
+		}
+		var value config.SettingValue
+		value, err = config.GetSetting(binding.key)
+		if err != nil || value.Source == config.SettingSourceDefault {
+			return
+		}
+		v := value.Value
+		if binding.convert != nil {
+			v = binding.convert(v)
+		}
+		// Set the value without marking the flag as changed, so it still
+		// counts as a default
+		if setErr := f.Value.Set(v); setErr != nil {
+			err = fmt.Errorf("invalid %s setting '%s': %w", binding.key, value.Value, setErr)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	timezone, err := config.GetSetting(config.SettingTimezone)
+	if err != nil {
+		return err
+	}
+	if timezone.Value != "" {
+		loc, err := time.LoadLocation(timezone.Value)
+		if err != nil {
+			return fmt.Errorf("unknown timezone '%s'", timezone.Value)
+		}
+		time.Local = loc
+	}
+	return nil
+}
+
+func settingNames() []string {
+	var names []string
+	for _, key := range config.SettingKeys {
+		names = append(names, key.Name)
+	}
+	return names
+}
+
+// configTargetPath returns the settings file changed by set, unset and edit
+func configTargetPath() string {
+	if configGlobal {
+		return config.GetSettingsPath()
+	}
+	return config.GetProfileSettingsPath(config.GetProfile())
+}
+
+// ConfigSetting describes a setting in list output
+type ConfigSetting struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Source      string `json:"source"`
+	Env         string `json:"env"`
+	Description string `json:"description"`
+}
+
+func runConfigList(cmd *cobra.Command, args []string) error {
+	var settings []ConfigSetting
+	for _, key := range config.SettingKeys {
+		value, err := config.GetSetting(key.Name)
+		if err != nil {
+			return err
+		}
+		settings = append(settings, ConfigSetting{
+			Key:         key.Name,
+			Value:       value.Value,
+			Source:      value.Source,
+			Env:         key.Env,
+			Description: key.Description,
+		})
+	}
+
+	if isStructuredOutput() {
+		return renderOutput(settings, settings)
+	}
+
+	headers := []string{"Key", "Value", "Source", "Description"}
+	var rows [][]string
+	for _, s := range settings {
+		source := s.Source
+		if source == config.SettingSourceEnv {
+			source = "$" + s.Env
+		}
+		rows = append(rows, []string{s.Key, s.Value, source, s.Description})
+	}
+	output.Table(headers, rows)
+	return nil
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	value, err := config.GetSetting(args[0])
+	if err != nil {
+		return err
+	}
+	if isStructuredOutput() {
+		return renderOutput(value, []config.SettingValue{value})
+	}
+	fmt.Println(value.Value)
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	key, value := args[0], args[1]
+	if value == "" {
+		return fmt.Errorf("value is empty (use 'frankie config unset %s' to remove the setting)", key)
+	}
+	if key == config.SettingOutput {
+		if _, err := output.ParseOptions(value, nil); err != nil {
+			return err
+		}
+	}
+
+	path := configTargetPath()
+	if err := config.SetSetting(path, key, value); err != nil {
+		return err
+	}
+	fmt.Printf("Set %s in %s\n", key, path)
+	warnSettingOverride(key)
+	return nil
+}
+
+func runConfigUnset(cmd *cobra.Command, args []string) error {
+	key := args[0]
+	path := configTargetPath()
+	if err := config.SetSetting(path, key, ""); err != nil {
+		return err
+	}
+	fmt.Printf("Removed %s from %s\n", key, path)
+	warnSettingOverride(key)
+	return nil
+}
+
+// warnSettingOverride notes when the environment takes precedence over a changed setting
+func warnSettingOverride(name string) {
+	key, err := config.LookupSettingKey(name)
+	if err == nil && os.Getenv(key.Env) != "" {
+		fmt.Printf("Note: $%s is set and takes precedence\n", key.Env)
+	}
+}
+
+// ConfigPath describes a file or directory in paths output
+type ConfigPath struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+func runConfigPaths(cmd *cobra.Command, args []string) error {
+	profile := config.GetProfile()
+	paths := []ConfigPath{
+		{Name: "config", Path: config.GetConfigDir()},
+		{Name: "settings", Path: config.GetSettingsPath()},
+		{Name: "profile settings", Path: config.GetProfileSettingsPath(profile)},
+		{Name: "credentials", Path: auth.CredentialsLocation()},
+		{Name: "daemon config", Path: config.GetDaemonConfigPath()},
+		{Name: "data", Path: config.GetDataDir()},
+		{Name: "database", Path: config.GetDatabasePath()},
+		{Name: "cache", Path: config.GetProfileCacheDir(profile)},
+		{Name: "logs", Path: config.GetProfileStateDir(profile)},
+	}
+
+	if isStructuredOutput() {
+		return renderOutput(paths, paths)
+	}
+
+	var rows [][]string
+	for _, p := range paths {
+		rows = append(rows, []string{p.Name, p.Path})
+	}
+	output.Table([]string{"Name", "Path"}, rows)
+	return nil
+}
+
+func runConfigEdit(cmd *cobra.Command, args []string) error {
+	path := configTargetPath()
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return fmt.Errorf("failed to create config directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(settingsTemplate()), 0600); err != nil {
+			return fmt.Errorf("failed to create settings: %w", err)
+		}
+	}
+
+	editor := strings.Fields(editorCommand())
+	c := exec.Command(editor[0], append(editor[1:], path)...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("failed to run editor: %w", err)
+	}
+
+	if _, err := config.LoadSettingsFile(path); err != nil {
+		return fmt.Errorf("%w (run 'frankie config edit' again to fix it)", err)
+	}
+	return nil
+}
+
+// editorCommand returns the editor to use for 'config edit'
+func editorCommand() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(env)); editor != "" {
+			return editor
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+// settingsTemplate is the initial content of a new settings file, listing all settings
+func settingsTemplate() string {
+	var b strings.Builder
+	b.WriteString("# Frankie settings. Environment variables take precedence over this file.\n")
+	for _, key := range config.SettingKeys {
+		fmt.Fprintf(&b, "\n# %s ($%s)\n", key.Description, key.Env)
+		fmt.Fprintf(&b, "#%s:", key.Name)
+		if key.Default != "" {
+			fmt.Fprintf(&b, " %s", key.Default)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -28,27 +29,20 @@ func runConnections(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	resp, err := client.Execute(api.MeQuery, "Me", nil)
+	connections, err := fetchConnections(client, "")
 	if err != nil {
-		return fmt.Errorf("failed to fetch connections: %w", err)
+		return err
 	}
 
-	var result models.MeResponse
-	if err := json.Unmarshal(resp.Data, &result); err != nil {
-		return fmt.Errorf("failed to parse response: %w", err)
+	if isStructuredOutput() {
+		return renderOutput(connections, connections)
 	}
 
-	connections := result.Me.Connections
-
 	if len(connections) == 0 {
 		fmt.Println("No connections found")
 		return nil
 	}
 
-	if getOutputFormat() == "json" {
-		return output.JSON(connections)
-	}
-
 	headers := []string{"Segment", "EAN", "Grid Operator", "Product", "Status", "Meter Type"}
 	var rows [][]string
 
@@ -77,3 +71,44 @@ func runConnections(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// fetchConnections returns the connections of all sites, or of a single site
+func fetchConnections(client *api.Client, siteRef string) ([]models.Connection, error) {
+	var variables map[string]interface{}
+	if siteRef != "" {
+		variables = map[string]interface{}{
+			"siteReference": siteRef,
+		}
+	}
+
+	resp, err := client.Execute(api.MeQuery, "Me", variables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch connections: %w", err)
+	}
+
+	var result models.MeResponse
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result.Me.Connections, nil
+}
+
+// connectionEANs returns the EAN of a site's connections by usage type
+// (electricity, gas and feedin). Feed-in is measured by the electricity meter.
+func connectionEANs(client *api.Client, siteRef string) (map[string]string, error) {
+	connections, err := fetchConnections(client, siteRef)
+	if err != nil {
+		return nil, err
+	}
+
+	eans := make(map[string]string)
+	for _, conn := range connections {
+		segment := strings.ToLower(conn.Segment)
+		eans[segment] = conn.EAN
+		if segment == "electricity" {
+			eans["feedin"] = conn.EAN
+		}
+	}
+	return eans, nil
+}
@@ -0,0 +1,306 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pietern/frankie/internal/api"
+	"github.com/pietern/frankie/internal/auth"
+	"github.com/pietern/frankie/internal/config"
+	"github.com/pietern/frankie/internal/daemon"
+	"github.com/pietern/frankie/internal/models"
+	"github.com/pietern/frankie/internal/store"
+)
+
+var (
+	daemonConfigFile string
+	daemonListen     string
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run scheduled jobs in the background",
+	Long: `Run scheduled jobs in a single long-lived process, sharing one login and
+one history database, instead of separate invocations from cron.
+
+Jobs are read from daemon.yaml in the config directory. Without that file,
+the daemon syncs prices after the day-ahead publication, syncs usage,
+invoices and battery sessions nightly, refreshes month summaries hourly and
+polls chargers every minute. Every job runs once at startup and then on
+its schedule, delayed by a random jitter.
+
+Example daemon.yaml:
+  listen: 127.0.0.1:9470
+  log:
+    format: json
+    level: info
+    file: daemon.log    # in the state directory (default: stderr)
+  jobs:
+    - name: prices
+      action: sync
+      datasets: [prices, site-prices]
+      schedule: daily 13:15
+      jitter: 10m
+    - name: chargers
+      action: chargers
+      schedule: every 1m
+
+Actions are sync (with datasets as for "frankie sync"), chargers and
+vehicles; the latter two log changes in charge state. Schedules are
+"every <duration>" or "daily HH:MM" in Dutch time.
+
+The last run, next run and last error of every job are served as JSON on
+/status of the listen address.`,
+	RunE: runDaemon,
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+	daemonCmd.Flags().StringVarP(&daemonConfigFile, "file", "f", "", "configuration file (default: daemon.yaml in the config directory)")
+	daemonCmd.Flags().StringVar(&daemonListen, "listen", "", "address of the status endpoint (overrides the configuration file)")
+}
+
+// daemonRunner implements the actions of daemon jobs
+type daemonRunner struct {
+	client     *api.Client
+	store      *store.Store
+	resolution string
+
+	// syncMu serializes sync jobs, which write to the same database
+	syncMu sync.Mutex
+
+	devicesMu sync.Mutex
+	devices   map[string]deviceStatus
+}
+
+// deviceStatus is the part of a charge state whose changes are logged
+type deviceStatus struct {
+	Reachable     bool
+	PluggedIn     bool
+	Charging      bool
+	FullyCharged  bool
+	DeliveryState string
+}
+
+func runDaemon(cmd *cobra.Command, args []string) error {
+	path := daemonConfigFile
+	if path == "" {
+		path = config.GetDaemonConfigPath()
+	}
+	cfg, err := daemon.LoadConfig(path, daemonConfigFile != "")
+	if err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("listen") {
+		cfg.Listen = daemonListen
+	}
+
+	logOutput := io.Writer(os.Stderr)
+	if cfg.Log.File != "" {
+		f, err := openLogFile(cfg.Log.File)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		logOutput = f
+	}
+	logger, err := cfg.Log.Logger(logOutput)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+
+	resolution, err := priceResolution(cfg.Resolution)
+	if err != nil {
+		return err
+	}
+
+	r := &daemonRunner{
+		resolution: resolution,
+		devices:    make(map[string]deviceStatus),
+	}
+
+	scheduler := daemon.NewScheduler(marketLocation())
+	for _, job := range cfg.Jobs {
+		run, err := r.action(job)
+		if err != nil {
+			return fmt.Errorf("job %s: %w", job.Name, err)
+		}
+		schedule, _ := daemon.ParseSchedule(job.Schedule)
+		scheduler.Add(daemon.Job{Name: job.Name, Schedule: schedule, Jitter: job.Jitter, Run: run})
+	}
+
+	r.client, err = newAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+	r.client.SetTokenSource(auth.NewManager(r.client).TokenSource())
+
+	dbFile := cfg.Database
+	if dbFile == "" {
+		dbFile = databasePath()
+	}
+	r.store, err = store.Open(dbFile)
+	if err != nil {
+		return err
+	}
+	defer r.store.Close()
+
+	ctx, stop := signalContext(cmd)
+	defer stop()
+
+	started := time.Now()
+	var wg sync.WaitGroup
+	var serveErr error
+	if cfg.Listen != "" {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"started": started,
+				"jobs":    scheduler.Status(),
+			})
+		})
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			serveErr = serveHTTP(ctx, cfg.Listen, mux)
+			if serveErr != nil {
+				stop()
+			}
+		}()
+	}
+
+	slog.Info("daemon started", "config", path, "jobs", len(cfg.Jobs))
+	scheduler.Run(ctx)
+	wg.Wait()
+	slog.Info("daemon stopped")
+	return serveErr
+}
+
+// openLogFile opens a log file for appending. Relative paths are in the state
+// directory of the active profile.
+func openLogFile(path string) (*os.File, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(config.GetProfileStateDir(config.GetProfile()), path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	return f, nil
+}
+
+// action returns the function that runs a job
+func (r *daemonRunner) action(job daemon.JobConfig) (func(ctx context.Context) error, error) {
+	switch job.Action {
+	case daemon.ActionSync:
+		if len(job.Datasets) == 0 {
+			return nil, fmt.Errorf("no datasets to sync")
+		}
+		for _, dataset := range job.Datasets {
+			if !isDataset(dataset) {
+				return nil, fmt.Errorf("unknown dataset: %s (must be one of %s)", dataset, strings.Join(allDatasets, ", "))
+			}
+		}
+		return func(ctx context.Context) error { return r.sync(ctx, job.Datasets) }, nil
+	case daemon.ActionChargers:
+		return func(ctx context.Context) error { return r.pollChargers() }, nil
+	case daemon.ActionVehicles:
+		return func(ctx context.Context) error { return r.pollVehicles() }, nil
+	}
+	return nil, fmt.Errorf("unknown action: %s (must be %s, %s or %s)", job.Action, daemon.ActionSync, daemon.ActionChargers, daemon.ActionVehicles)
+}
+
+// sync runs a sync job. A sync that is interrupted by shutdown stops after
+// the current request; progress up to there is kept.
+func (r *daemonRunner) sync(ctx context.Context, datasets []string) error {
+	r.syncMu.Lock()
+	defer r.syncMu.Unlock()
+
+	s := &syncer{client: r.client, store: r.store, resolution: r.resolution}
+	err := s.run(ctx, datasets)
+	for _, result := range s.results {
+		slog.Info("synced", "dataset", result.Dataset, "key", result.Key, "from", result.From, "to", result.To, "records", result.Records)
+	}
+	if err != nil && ctx.Err() != nil {
+		slog.Info("sync interrupted", "datasets", datasets)
+		return nil
+	}
+	return err
+}
+
+func (r *daemonRunner) pollChargers() error {
+	chargers, err := fetchChargers(r.client)
+	if err != nil {
+		if errors.Is(err, api.ErrSmartChargingNotEnabled) {
+			return nil
+		}
+		return err
+	}
+	for _, c := range chargers {
+		r.observeDevice("charger", c.ID, c.IsReachable, c.ChargeState)
+	}
+	return nil
+}
+
+func (r *daemonRunner) pollVehicles() error {
+	vehicles, err := fetchVehicles(r.client)
+	if err != nil {
+		if errors.Is(err, api.ErrSmartChargingNotEnabled) {
+			return nil
+		}
+		return err
+	}
+	for _, v := range vehicles {
+		r.observeDevice("vehicle", v.ID, v.IsReachable, v.ChargeState)
+	}
+	return nil
+}
+
+// observeDevice logs a device's charge state when it has changed since the last poll
+func (r *daemonRunner) observeDevice(kind, id string, reachable bool, state *models.ChargeState) {
+	status := deviceStatus{Reachable: reachable}
+	attrs := []interface{}{"kind", kind, "device", id, "reachable", reachable}
+	if state != nil {
+		status.PluggedIn = state.IsPluggedIn
+		status.Charging = state.IsCharging
+		status.FullyCharged = state.IsFullyCharged
+		status.DeliveryState = state.PowerDeliveryState
+		attrs = append(attrs,
+			"pluggedIn", state.IsPluggedIn,
+			"charging", state.IsCharging,
+			"fullyCharged", state.IsFullyCharged,
+			"powerDeliveryState", state.PowerDeliveryState,
+			"batteryLevel", state.BatteryLevel,
+			"chargeRate", state.ChargeRate,
+		)
+	}
+
+	r.devicesMu.Lock()
+	previous, seen := r.devices[kind+"/"+id]
+	r.devices[kind+"/"+id] = status
+	r.devicesMu.Unlock()
+
+	if !seen || previous != status {
+		slog.Info("charge state changed", attrs...)
+		return
+	}
+	slog.Debug("charge state", attrs...)
+}
@@ -0,0 +1,832 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/pietern/frankie/internal/api"
+	"github.com/pietern/frankie/internal/auth"
+	"github.com/pietern/frankie/internal/config"
+	frankieErrors "github.com/pietern/frankie/internal/errors"
+	"github.com/pietern/frankie/internal/models"
+	"github.com/pietern/frankie/internal/output"
+)
+
+var (
+	dashboardSite       string
+	dashboardDate       string
+	dashboardResolution int
+	dashboardInterval   time.Duration
+)
+
+var dashboardCmd = &cobra.Command{
+	Use:   "dashboard",
+	Short: "Show prices, costs, usage and devices in an interactive dashboard",
+	Long: `Show a full-screen dashboard with tabs for the price chart, the month
+summary, usage, smart batteries and smart charging. Data is refreshed every
+--interval.
+
+Keys:
+  tab, shift+tab, 1-5   switch tabs
+  left, right           previous or next date
+  t                     back to today
+  s, S                  next or previous site
+  r                     refresh now
+  q                     quit`,
+	Args: cobra.NoArgs,
+	RunE: runDashboard,
+}
+
+func init() {
+	rootCmd.AddCommand(dashboardCmd)
+	dashboardCmd.Flags().StringVarP(&dashboardSite, "site", "s", "", "site reference to start with (default: the first site)")
+	dashboardCmd.Flags().StringVarP(&dashboardDate, "date", "d", "", "date to start with (YYYY-MM-DD, default: today)")
+	dashboardCmd.Flags().IntVarP(&dashboardResolution, "resolution", "r", resolution60Min, "price resolution in minutes of public prices (15 or 60)")
+	dashboardCmd.Flags().DurationVar(&dashboardInterval, "interval", time.Minute, "how often to refresh data from the API")
+	bindSetting(dashboardCmd.Flags(), "site", config.SettingSite)
+	bindSetting(dashboardCmd.Flags(), "resolution", config.SettingResolution)
+	bindSiteFlag(dashboardCmd, "site")
+	bindDateFlag(dashboardCmd, dashboardCmd.Flags(), "date")
+}
+
+// Tabs of the dashboard. Each tab shows the data of one source.
+const (
+	tabPrices = iota
+	tabSummary
+	tabUsage
+	tabBattery
+	tabCharging
+)
+
+var dashboardTabs = []string{"Prices", "Summary", "Usage", "Battery", "Charging"}
+
+var (
+	dashboardTitleStyle     = lipgloss.NewStyle().Bold(true)
+	dashboardTabStyle       = lipgloss.NewStyle().Padding(0, 1)
+	dashboardActiveTabStyle = dashboardTabStyle.Bold(true).Reverse(true)
+	dashboardFaintStyle     = lipgloss.NewStyle().Faint(true)
+	dashboardErrorStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	dashboardBarStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+	dashboardNowStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("3")).Bold(true)
+)
+
+func runDashboard(cmd *cobra.Command, args []string) error {
+	if !isTerminal(os.Stdout) {
+		return fmt.Errorf("the dashboard needs a terminal")
+	}
+	if dashboardInterval < 10*time.Second {
+		return fmt.Errorf("invalid interval: %s (must be at least 10s)", dashboardInterval)
+	}
+	resolution, err := priceResolution(dashboardResolution)
+	if err != nil {
+		return err
+	}
+
+	date := dashboardDate
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return fmt.Errorf("invalid date '%s' (expected YYYY-MM-DD)", date)
+	}
+
+	client, err := newAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+	// The dashboard runs for a long time, so the token is renewed as needed
+	client.SetTokenSource(auth.NewManager(client).TokenSource())
+
+	all, err := fetchSites(client)
+	if err != nil {
+		return err
+	}
+	sites := (siteFilter{}).apply(all)
+	site := 0
+	if dashboardSite != "" {
+		selected, err := selectSite(all, dashboardSite, siteFilter{})
+		if err != nil {
+			return err
+		}
+		site = -1
+		for i := range sites {
+			if sites[i].Reference == selected.Reference {
+				site = i
+			}
+		}
+		// A site whose delivery has ended is only shown when asked for
+		if site < 0 {
+			sites = append(sites, selected)
+			site = len(sites) - 1
+		}
+	}
+
+	m := &dashboardModel{
+		client:     client,
+		resolution: resolution,
+		interval:   dashboardInterval,
+		sites:      sites,
+		site:       site,
+		date:       date,
+		results:    make(map[int]dashboardResult),
+	}
+	_, err = tea.NewProgram(m, tea.WithAltScreen()).Run()
+	return err
+}
+
+// dashboardResult is the outcome of fetching the data of a tab
+type dashboardResult struct {
+	tab int
+	// key identifies the site and date the data is for
+	key  string
+	data interface{}
+	err  error
+}
+
+// dashboardTick triggers a refresh
+type dashboardTick time.Time
+
+// dashboardBattery is a smart battery with its details
+type dashboardBattery struct {
+	Battery models.SmartBattery
+	Details *models.SmartBatteryDetailsResponse
+}
+
+// dashboardCharging holds the smart vehicles and chargers
+type dashboardCharging struct {
+	Vehicles []models.EnodeVehicle
+	Chargers []models.EnodeCharger
+	// VehiclesErr and ChargersErr are set if either could not be fetched
+	VehiclesErr error
+	ChargersErr error
+}
+
+// dashboardModel is the state of the dashboard
+type dashboardModel struct {
+	client     *api.Client
+	resolution string
+	interval   time.Duration
+
+	sites []models.Site
+	site  int
+	date  string
+	tab   int
+
+	results map[int]dashboardResult
+	pending int
+	updated time.Time
+
+	width  int
+	height int
+}
+
+func (m *dashboardModel) Init() tea.Cmd {
+	return tea.Batch(m.fetch(tabPrices, tabSummary, tabUsage, tabBattery, tabCharging), m.tick())
+}
+
+func (m *dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+
+	case dashboardTick:
+		return m, tea.Batch(m.fetch(tabPrices, tabSummary, tabUsage, tabBattery, tabCharging), m.tick())
+
+	case dashboardResult:
+		m.pending--
+		// Results for a site or date that is no longer shown are dropped
+		if msg.key == m.key(msg.tab) {
+			m.results[msg.tab] = msg
+		}
+		if m.pending == 0 {
+			m.updated = time.Now()
+		}
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "tab":
+			m.tab = (m.tab + 1) % len(dashboardTabs)
+		case "shift+tab":
+			m.tab = (m.tab + len(dashboardTabs) - 1) % len(dashboardTabs)
+		case "1", "2", "3", "4", "5":
+			m.tab = int(msg.String()[0] - '1')
+		case "left", "h":
+			return m, m.moveDate(-1)
+		case "right", "l":
+			return m, m.moveDate(1)
+		case "t":
+			m.date = time.Now().Format("2006-01-02")
+			return m, m.fetch(tabPrices, tabUsage)
+		case "s", "down", "j":
+			return m, m.moveSite(1)
+		case "S", "up", "k":
+			return m, m.moveSite(-1)
+		case "r":
+			return m, m.fetch(tabPrices, tabSummary, tabUsage, tabBattery, tabCharging)
+		}
+	}
+	return m, nil
+}
+
+func (m *dashboardModel) tick() tea.Cmd {
+	return tea.Tick(m.interval, func(t time.Time) tea.Msg { return dashboardTick(t) })
+}
+
+// moveDate moves to an earlier or later date, up to the last date with published prices
+func (m *dashboardModel) moveDate(days int) tea.Cmd {
+	current, err := time.Parse("2006-01-02", m.date)
+	if err != nil {
+		return nil
+	}
+	date := current.AddDate(0, 0, days).Format("2006-01-02")
+	if date > latestPriceDate() {
+		return nil
+	}
+	m.date = date
+	return m.fetch(tabPrices, tabUsage)
+}
+
+// moveSite moves to the next or previous site
+func (m *dashboardModel) moveSite(step int) tea.Cmd {
+	if len(m.sites) < 2 {
+		return nil
+	}
+	m.site = (m.site + step + len(m.sites)) % len(m.sites)
+	return m.fetch(tabPrices, tabSummary, tabUsage)
+}
+
+// siteRef returns the reference of the selected site, or "" if the account has no sites
+func (m *dashboardModel) siteRef() string {
+	if len(m.sites) == 0 {
+		return ""
+	}
+	return m.sites[m.site].Reference
+}
+
+// key identifies the site and date the data of a tab depends on
+func (m *dashboardModel) key(tab int) string {
+	switch tab {
+	case tabPrices, tabUsage:
+		return m.siteRef() + " " + m.date
+	case tabSummary:
+		return m.siteRef()
+	}
+	return ""
+}
+
+// fetch fetches the data of tabs in the background
+func (m *dashboardModel) fetch(tabs ...int) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, tab := range tabs {
+		client, siteRef, date, key := m.client, m.siteRef(), m.date, m.key(tab)
+		resolution := m.resolution
+		m.pending++
+		cmds = append(cmds, func() tea.Msg {
+			data, err := fetchDashboardData(client, tab, siteRef, date, resolution)
+			return dashboardResult{tab: tab, key: key, data: data, err: err}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// errNoSites is returned for tabs that need a site when the account has none
+var errNoSites = errors.New("no sites found")
+
+// fetchDashboardData fetches the data shown on a tab
+func fetchDashboardData(client *api.Client, tab int, siteRef, date, resolution string) (interface{}, error) {
+	switch tab {
+	case tabPrices:
+		if siteRef != "" {
+			return fetchCustomerPrices(client, date, siteRef)
+		}
+		return fetchPublicPrices(client, date, resolution)
+
+	case tabSummary:
+		if siteRef == "" {
+			return nil, errNoSites
+		}
+		return fetchMonthSummary(client, siteRef)
+
+	case tabUsage:
+		if siteRef == "" {
+			return nil, errNoSites
+		}
+		return fetchUsage(client, siteRef, date)
+
+	case tabBattery:
+		batteries, err := fetchBatteries(client)
+		if err != nil {
+			return nil, err
+		}
+		var result []dashboardBattery
+		for _, b := range batteries {
+			details, err := fetchBatteryDetails(client, b.ID)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, dashboardBattery{Battery: b, Details: details})
+		}
+		return result, nil
+
+	case tabCharging:
+		var result dashboardCharging
+		result.Vehicles, result.VehiclesErr = fetchVehicles(client)
+		result.Chargers, result.ChargersErr = fetchChargers(client)
+		return result, nil
+	}
+	return nil, fmt.Errorf("unknown tab %d", tab)
+}
+
+func (m *dashboardModel) View() string {
+	if m.width == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(m.viewHeader())
+	b.WriteString("\n")
+	b.WriteString(m.viewTabs())
+	b.WriteString("\n\n")
+
+	result, ok := m.results[m.tab]
+	switch {
+	case !ok:
+		b.WriteString(dashboardFaintStyle.Render("Loading…"))
+	case result.err != nil:
+		b.WriteString(dashboardErrorMessage(result.err))
+	default:
+		switch m.tab {
+		case tabPrices:
+			b.WriteString(m.viewPrices(result.data.(*models.MarketPrices)))
+		case tabSummary:
+			b.WriteString(m.viewSummary(result.data.(*models.MonthSummary)))
+		case tabUsage:
+			b.WriteString(m.viewUsage(result.data.(*models.PeriodUsageAndCosts)))
+		case tabBattery:
+			b.WriteString(m.viewBatteries(result.data.([]dashboardBattery)))
+		case tabCharging:
+			b.WriteString(m.viewCharging(result.data.(dashboardCharging)))
+		}
+	}
+
+	// Fit the page to the screen, with the help at the bottom
+	lines := strings.Split(strings.TrimRight(b.String(), "\n"), "\n")
+	height := max(1, m.height-1)
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n") + "\n" + m.viewHelp()
+}
+
+func (m *dashboardModel) viewHeader() string {
+	left := dashboardTitleStyle.Render("Frankie")
+	if len(m.sites) > 0 {
+		left += "  " + siteLabel(m.sites[m.site])
+		if len(m.sites) > 1 {
+			left += dashboardFaintStyle.Render(fmt.Sprintf(" (%d/%d)", m.site+1, len(m.sites)))
+		}
+	}
+
+	right := dashboardDateLabel(m.date)
+	switch {
+	case m.pending > 0:
+		right += dashboardFaintStyle.Render("  refreshing…")
+	case !m.updated.IsZero():
+		right += dashboardFaintStyle.Render("  updated " + m.updated.Format("15:04:05"))
+	}
+
+	gap := m.width - lipgloss.Width(left) - lipgloss.Width(right)
+	if gap < 2 {
+		return left + "\n" + right
+	}
+	return left + strings.Repeat(" ", gap) + right
+}
+
+// dashboardDateLabel describes a date, and whether it is today, tomorrow or yesterday
+func dashboardDateLabel(date string) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	label := t.Format("Monday 2 January 2006")
+	for _, name := range relativeDateNames {
+		if relative, _ := resolveRelativeDate(name); relative == date {
+			label += " (" + name + ")"
+		}
+	}
+	return label
+}
+
+func (m *dashboardModel) viewTabs() string {
+	var tabs []string
+	for i, name := range dashboardTabs {
+		label := fmt.Sprintf("%d %s", i+1, name)
+		if i == m.tab {
+			tabs = append(tabs, dashboardActiveTabStyle.Render(label))
+		} else {
+			tabs = append(tabs, dashboardTabStyle.Render(label))
+		}
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
+}
+
+func (m *dashboardModel) viewHelp() string {
+	help := "tab switch · ←/→ date · t today"
+	if len(m.sites) > 1 {
+		help += " · s/S site"
+	}
+	help += " · r refresh · q quit"
+	return dashboardFaintStyle.Render(help)
+}
+
+// dashboardErrorMessage describes why the data of a tab is not available
+func dashboardErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, api.ErrSmartTradingNotEnabled):
+		return "Smart trading is not enabled for your account"
+	case errors.Is(err, api.ErrSmartChargingNotEnabled):
+		return "Smart charging is not enabled for your account"
+	case errors.Is(err, errNoSites):
+		return "No sites found"
+	}
+	return dashboardErrorStyle.Render(frankieErrors.Format(err))
+}
+
+// chartHeight returns the height of a chart that leaves the given number of
+// lines for the rest of the page, so the page fits the terminal
+func (m *dashboardModel) chartHeight(reserved int) int {
+	return max(3, min(12, m.height-reserved))
+}
+
+func (m *dashboardModel) viewPrices(prices *models.MarketPrices) string {
+	if prices == nil || len(prices.ElectricityPrices) == 0 {
+		return fmt.Sprintf("No prices published for %s yet", m.date)
+	}
+
+	now := time.Now()
+	highlight := -1
+	values := make([]float64, len(prices.ElectricityPrices))
+	labels := make([]string, len(prices.ElectricityPrices))
+	for i, p := range prices.ElectricityPrices {
+		values[i] = allInPrice(p)
+		if from := p.From.Local(); from.Minute() == 0 {
+			labels[i] = from.Format("15")
+		}
+		if !now.Before(p.From) && now.Before(p.Till) {
+			highlight = i
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("Electricity all-in price per kWh\n\n")
+	b.WriteString(barChart(values, labels, highlight, m.width, m.chartHeight(14), func(v float64) string {
+		return fmt.Sprintf("€%.2f", v)
+	}))
+	b.WriteString("\n\n")
+
+	lowest, highest := 0, 0
+	sum := 0.0
+	for i, v := range values {
+		if v < values[lowest] {
+			lowest = i
+		}
+		if v > values[highest] {
+			highest = i
+		}
+		sum += v
+	}
+	electricity := prices.ElectricityPrices
+	var stats []string
+	if highlight >= 0 {
+		p := electricity[highlight]
+		stats = append(stats, dashboardNowStyle.Render(fmt.Sprintf("Now €%.4f", values[highlight]))+
+			fmt.Sprintf(" (%s–%s)", p.From.Local().Format("15:04"), p.Till.Local().Format("15:04")))
+	}
+	stats = append(stats,
+		fmt.Sprintf("Lowest €%.4f at %s", values[lowest], electricity[lowest].From.Local().Format("15:04")),
+		fmt.Sprintf("Highest €%.4f at %s", values[highest], electricity[highest].From.Local().Format("15:04")),
+		fmt.Sprintf("Average €%.4f", sum/float64(len(values))),
+	)
+	b.WriteString(lipgloss.NewStyle().Width(m.width).Render(strings.Join(stats, " · ")))
+
+	if gas := priceAt(prices.GasPrices, now); gas != nil {
+		fmt.Fprintf(&b, "\nGas €%.4f per m³", allInPrice(*gas))
+	} else if len(prices.GasPrices) > 0 {
+		fmt.Fprintf(&b, "\nGas €%.4f per m³", allInPrice(prices.GasPrices[0]))
+	}
+	return b.String()
+}
+
+// allInPrice returns the all-in price, which Belgium prices do not have
+func allInPrice(p models.Price) float64 {
+	if p.AllInPrice == 0 {
+		return p.TotalPrice()
+	}
+	return p.AllInPrice
+}
+
+func (m *dashboardModel) viewSummary(summary *models.MonthSummary) string {
+	if summary == nil {
+		return "No summary data available"
+	}
+
+	now := time.Now()
+	day := now.Day()
+	days := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, time.Local).Day()
+	barWidth := max(10, min(40, m.width-40))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", now.Format("January 2006"))
+
+	keys := []string{"Month", "Costs", "Expected to date", "Last meter reading", "Gas included"}
+	pairs := map[string]string{
+		"Month": fmt.Sprintf("%s  day %d of %d", meter(float64(day)/float64(days), barWidth), day, days),
+		"Costs": fmt.Sprintf("%s  €%.2f of €%.2f expected this month",
+			meter(ratio(summary.ActualCostsUntilLastMeterReadingDate, summary.ExpectedCosts), barWidth),
+			summary.ActualCostsUntilLastMeterReadingDate, summary.ExpectedCosts),
+		"Expected to date":   fmt.Sprintf("€%.2f (%s)", summary.ExpectedCostsUntilLastMeterReadingDate, costsDifference(summary)),
+		"Last meter reading": fmt.Sprintf("%s (%.0f%% complete)", formatDate(summary.LastMeterReadingDate), summary.MeterReadingDayCompleteness*100),
+		"Gas included":       "Yes",
+	}
+	if summary.GasExcluded {
+		pairs["Gas included"] = "No"
+	}
+	output.KeyValueOrderedTo(&b, keys, pairs)
+	return b.String()
+}
+
+// costsDifference describes the actual costs relative to the expected costs
+func costsDifference(summary *models.MonthSummary) string {
+	diff := summary.ActualCostsUntilLastMeterReadingDate - summary.ExpectedCostsUntilLastMeterReadingDate
+	if diff > 0 {
+		return fmt.Sprintf("€%.2f above", diff)
+	}
+	return fmt.Sprintf("€%.2f below", -diff)
+}
+
+// noUsageMessage explains that there is no usage for the selected date
+func (m *dashboardModel) noUsageMessage() string {
+	message := fmt.Sprintf("No usage for %s yet", m.date)
+	if site := m.sites[m.site]; site.LastMeterReadingDate != "" {
+		message += fmt.Sprintf(" (the last meter reading is of %s)", formatDate(site.LastMeterReadingDate))
+	}
+	return message
+}
+
+func (m *dashboardModel) viewUsage(usage *models.PeriodUsageAndCosts) string {
+	if usage == nil {
+		return m.noUsageMessage()
+	}
+
+	var rows [][]string
+	for _, c := range []struct {
+		name     string
+		category *models.EnergyCategory
+	}{
+		{"Electricity", usage.Electricity},
+		{"Gas", usage.Gas},
+		{"Feed-in", usage.FeedIn},
+	} {
+		if c.category == nil || len(c.category.Items) == 0 {
+			continue
+		}
+		rows = append(rows, []string{
+			c.name,
+			fmt.Sprintf("%.2f %s", c.category.UsageTotal, c.category.Unit),
+			fmt.Sprintf("€%.2f", c.category.CostsTotal),
+		})
+	}
+	if len(rows) == 0 {
+		return m.noUsageMessage()
+	}
+
+	var b strings.Builder
+	output.TableTo(&b, []string{"Type", "Usage", "Costs"}, rows)
+
+	if electricity := usage.Electricity; electricity != nil && len(electricity.Items) > 0 {
+		values := make([]float64, len(electricity.Items))
+		labels := make([]string, len(electricity.Items))
+		for i, item := range electricity.Items {
+			values[i] = item.Usage
+			if t := parseTimestamp(item.From); t.Local().Minute() == 0 {
+				labels[i] = t.Local().Format("15")
+			}
+		}
+		fmt.Fprintf(&b, "\nElectricity usage per interval (%s)\n\n", electricity.Unit)
+		b.WriteString(barChart(values, labels, -1, m.width, m.chartHeight(16), func(v float64) string {
+			return fmt.Sprintf("%.2f", v)
+		}))
+	}
+	return b.String()
+}
+
+func (m *dashboardModel) viewBatteries(batteries []dashboardBattery) string {
+	if len(batteries) == 0 {
+		return "No batteries found"
+	}
+
+	barWidth := max(10, min(40, m.width-40))
+	var b strings.Builder
+	for i, battery := range batteries {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s\n", dashboardTitleStyle.Render(fmt.Sprintf("%s %.1f kWh (%s)", battery.Battery.Brand, battery.Battery.Capacity, battery.Battery.ID)))
+
+		keys := []string{"Charge", "Status", "Mode", "Total result", "Last update"}
+		pairs := map[string]string{}
+		if details := battery.Details; details != nil {
+			if summary := details.SmartBatterySummary; summary != nil {
+				pairs["Charge"] = fmt.Sprintf("%s  %.0f%%", meter(summary.LastKnownStateOfCharge/100, barWidth), summary.LastKnownStateOfCharge)
+				pairs["Status"] = summary.LastKnownStatus
+				pairs["Total result"] = fmt.Sprintf("€%.2f", summary.TotalResult)
+				pairs["Last update"] = formatDate(summary.LastUpdate)
+			}
+			if details.SmartBattery != nil && details.SmartBattery.Settings != nil {
+				pairs["Mode"] = details.SmartBattery.Settings.BatteryMode
+			}
+		}
+		output.KeyValueOrderedTo(&b, keys, pairs)
+	}
+	return b.String()
+}
+
+func (m *dashboardModel) viewCharging(charging dashboardCharging) string {
+	barWidth := max(10, min(40, m.width-40))
+
+	var b strings.Builder
+	b.WriteString(dashboardTitleStyle.Render("Vehicles") + "\n")
+	switch {
+	case charging.VehiclesErr != nil:
+		b.WriteString(dashboardErrorMessage(charging.VehiclesErr) + "\n")
+	case len(charging.Vehicles) == 0:
+		b.WriteString("No smart vehicles found\n")
+	}
+	for _, v := range charging.Vehicles {
+		b.WriteString(chargingDevice(v.Information, v.ChargeState, v.IsReachable, v.CanSmartCharge, barWidth))
+	}
+
+	b.WriteString("\n" + dashboardTitleStyle.Render("Chargers") + "\n")
+	switch {
+	case charging.ChargersErr != nil:
+		b.WriteString(dashboardErrorMessage(charging.ChargersErr) + "\n")
+	case len(charging.Chargers) == 0:
+		b.WriteString("No smart chargers found\n")
+	}
+	for _, c := range charging.Chargers {
+		b.WriteString(chargingDevice(c.Information, c.ChargeState, c.IsReachable, c.CanSmartCharge, barWidth))
+	}
+	return b.String()
+}
+
+// chargingDevice describes a smart vehicle or charger in two lines
+func chargingDevice(info *models.DeviceInfo, state *models.ChargeState, reachable, smart bool, barWidth int) string {
+	name := "Unknown device"
+	if info != nil {
+		name = strings.TrimSpace(info.Brand + " " + info.Model)
+	}
+	status := []string{"offline"}
+	if reachable {
+		status = []string{"online"}
+	}
+	if smart {
+		status = append(status, "smart charging")
+	}
+	line := fmt.Sprintf("%s (%s)\n", name, strings.Join(status, ", "))
+
+	if state == nil {
+		return line
+	}
+	var details []string
+	if state.BatteryLevel > 0 {
+		details = append(details, fmt.Sprintf("%s  %.0f%%", meter(state.BatteryLevel/100, barWidth), state.BatteryLevel))
+	}
+	if state.Range > 0 {
+		details = append(details, fmt.Sprintf("%.0f km", state.Range))
+	}
+	switch {
+	case state.IsCharging:
+		details = append(details, fmt.Sprintf("charging at %.1f kW", state.ChargeRate))
+	case state.IsFullyCharged:
+		details = append(details, "fully charged")
+	case state.IsPluggedIn:
+		details = append(details, "plugged in")
+	default:
+		details = append(details, "not plugged in")
+	}
+	if state.ChargeLimit > 0 {
+		details = append(details, fmt.Sprintf("limit %.0f%%", state.ChargeLimit))
+	}
+	return line + "  " + strings.Join(details, " · ") + "\n"
+}
+
+// ratio returns part/total, or 0 if total is 0
+func ratio(part, total float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return part / total
+}
+
+// meter renders a fraction between 0 and 1 as a horizontal bar
+func meter(fraction float64, width int) string {
+	filled := int(math.Round(math.Max(0, math.Min(1, fraction)) * float64(width)))
+	return dashboardBarStyle.Render(strings.Repeat("█", filled)) +
+		dashboardFaintStyle.Render(strings.Repeat("░", width-filled))
+}
+
+// chartBlocks are the eighths of a character cell used to draw bars
+var chartBlocks = []string{" ", "▁", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
+
+// barChart renders values as vertical bars, with the bar at highlight (-1 for
+// none) in another color. Labels are shown under the bars where they fit.
+// If there are more values than fit in width, neighbouring values are averaged.
+func barChart(values []float64, labels []string, highlight, width, height int, format func(float64) string) string {
+	lo, hi := 0.0, 0.0
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	top, bottom := format(hi), format(lo)
+	axisWidth := max(lipgloss.Width(top), lipgloss.Width(bottom)) + 1
+	if hi == lo {
+		hi = lo + 1
+	}
+
+	// Average neighbouring values until the bars fit
+	available := max(1, width-axisWidth)
+	if step := (len(values) + available - 1) / available; step > 1 {
+		var merged []float64
+		var mergedLabels []string
+		for i := 0; i < len(values); i += step {
+			end := min(i+step, len(values))
+			sum := 0.0
+			for _, v := range values[i:end] {
+				sum += v
+			}
+			merged = append(merged, sum/float64(end-i))
+			mergedLabels = append(mergedLabels, labels[i])
+		}
+		values, labels = merged, mergedLabels
+		if highlight >= 0 {
+			highlight /= step
+		}
+	}
+
+	// Bars are up to 3 cells wide, with a gap if there is room
+	slot := max(1, min(4, available/len(values)))
+	barWidth := slot
+	if slot > 1 {
+		barWidth = slot - 1
+	}
+
+	var b strings.Builder
+	for row := 0; row < height; row++ {
+		label := ""
+		switch row {
+		case 0:
+			label = top
+		case height - 1:
+			label = bottom
+		}
+		b.WriteString(dashboardFaintStyle.Render(fmt.Sprintf("%*s ", axisWidth-1, label)))
+
+		level := float64((height - 1 - row) * 8)
+		for i, v := range values {
+			eighths := int(math.Round((v-lo)/(hi-lo)*float64(height*8) - level))
+			cell := strings.Repeat(chartBlocks[max(0, min(8, eighths))], barWidth)
+			style := dashboardBarStyle
+			if i == highlight {
+				style = dashboardNowStyle
+			}
+			b.WriteString(style.Render(cell) + strings.Repeat(" ", slot-barWidth))
+		}
+		b.WriteString("\n")
+	}
+
+	// Place labels under their bar, skipping those that would overlap
+	axis := []rune(strings.Repeat(" ", axisWidth+len(values)*slot))
+	next := 0
+	for i, label := range labels {
+		pos := axisWidth + i*slot
+		if label == "" || pos < next || pos+len(label) > len(axis) {
+			continue
+		}
+		copy(axis[pos:], []rune(label))
+		next = pos + len(label) + 1
+	}
+	b.WriteString(dashboardFaintStyle.Render(strings.TrimRight(string(axis), " ")))
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBarChart(t *testing.T) {
+	format := func(v float64) string { return fmt.Sprintf("%.1f", v) }
+
+	tests := []struct {
+		name      string
+		values    []float64
+		labels    []string
+		highlight int
+		width     int
+		height    int
+		want      string
+	}{
+		{
+			name:      "bars in eighths",
+			values:    []float64{0, 1, 2, 4},
+			labels:    []string{"a", "b", "c", "d"},
+			highlight: 2,
+			width:     20,
+			height:    2,
+			want: "4.0             ███ \n" +
+				"0.0     ▄▄▄ ███ ███ \n" +
+				"    a   b   c   d",
+		},
+		{
+			// Pairs are averaged to fit, and labels that would overlap are skipped
+			name:      "merged",
+			values:    []float64{1, 1, 1, 1, 2, 2},
+			labels:    []string{"00", "01", "02", "03", "04", "05"},
+			highlight: -1,
+			width:     7,
+			height:    1,
+			want:      "2.0 ▄▄█\n    00",
+		},
+		{
+			name:      "negative",
+			values:    []float64{-1, 1},
+			labels:    []string{"x", ""},
+			highlight: -1,
+			width:     20,
+			height:    2,
+			want: " 1.0     ███ \n" +
+				"-1.0     ███ \n" +
+				"     x",
+		},
+		{
+			name:      "flat",
+			values:    []float64{0, 0},
+			labels:    []string{"", ""},
+			highlight: -1,
+			width:     10,
+			height:    1,
+			want:      "0.0       \n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := barChart(tt.values, tt.labels, tt.highlight, tt.width, tt.height, format)
+			if got != tt.want {
+				t.Errorf("barChart() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pietern/frankie/internal/output"
+	"github.com/pietern/frankie/internal/store"
+)
+
+const (
+	// histogramBarWidth is the width of the bar for the largest bucket
+	histogramBarWidth = 40
+)
+
+var (
+	dbReportSite    string
+	dbReportFrom    string
+	dbReportTo      string
+	dbReportSegment string
+	dbReportBucket  float64
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Query the local history database",
+	Long: `Query the history downloaded with 'frankie sync'.
+
+These commands work offline and do not require login.`,
+}
+
+var dbQueryCmd = &cobra.Command{
+	Use:   "query <sql>",
+	Short: "Run a SQL query against the local history",
+	Long: `Run a read-only SQL query against the local history database.
+
+Tables: prices, usage, invoices, month_summaries, battery_sessions, sync_state.
+Public market prices have an empty site_reference.
+
+Examples:
+  frankie db query 'SELECT segment, COUNT(*) FROM prices GROUP BY segment'
+  frankie db query 'SELECT date, SUM(costs) FROM usage GROUP BY date' -o json
+  echo 'SELECT * FROM invoices' | frankie db query -`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDBQuery,
+}
+
+var dbReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Show canned reports from the local history",
+}
+
+var dbReportMonthlyCostsCmd = &cobra.Command{
+	Use:   "monthly-costs",
+	Short: "Show usage and costs per month",
+	RunE:  runDBReportMonthlyCosts,
+}
+
+var dbReportPriceHistogramCmd = &cobra.Command{
+	Use:   "price-histogram",
+	Short: "Show the distribution of all-in prices",
+	RunE:  runDBReportPriceHistogram,
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbQueryCmd)
+	dbCmd.AddCommand(dbReportCmd)
+	dbReportCmd.AddCommand(dbReportMonthlyCostsCmd)
+	dbReportCmd.AddCommand(dbReportPriceHistogramCmd)
+
+	dbCmd.PersistentFlags().StringVar(&dbPath, "db", "", "database path (default: history.db in the data directory)")
+
+	dbReportCmd.PersistentFlags().StringVarP(&dbReportSite, "site", "s", "", "site reference (default: all sites, or public prices)")
+	dbReportCmd.PersistentFlags().StringVar(&dbReportFrom, "from", "", "start date (YYYY-MM-DD)")
+	dbReportCmd.PersistentFlags().StringVar(&dbReportTo, "to", "", "end date (YYYY-MM-DD)")
+	bindSiteFlag(dbReportCmd, "site")
+	bindDateFlag(dbReportCmd, dbReportCmd.PersistentFlags(), "from")
+	bindDateFlag(dbReportCmd, dbReportCmd.PersistentFlags(), "to")
+
+	dbReportPriceHistogramCmd.Flags().StringVar(&dbReportSegment, "segment", "electricity", "segment: electricity or gas")
+	dbReportPriceHistogramCmd.Flags().Float64Var(&dbReportBucket, "bucket", 0.05, "bucket width in euros")
+}
+
+// openHistory opens the local history database for reading
+func openHistory() (*store.Store, error) {
+	path := dbPath
+	if path == "" {
+		path = databasePath()
+	}
+
+	if err := store.Upgrade(path); err != nil {
+		fmt.Fprintln(os.Stderr, "Warning:", err)
+	}
+
+	db, err := store.OpenReadOnly(path)
+	if errors.Is(err, store.ErrNoDatabase) {
+		return nil, fmt.Errorf("%w at %s (run 'frankie sync' first)", err, path)
+	}
+	return db, err
+}
+
+func runDBQuery(cmd *cobra.Command, args []string) error {
+	query := args[0]
+	if query == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read from stdin: %w", err)
+		}
+		query = string(data)
+	}
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return fmt.Errorf("query is required")
+	}
+
+	db, err := openHistory()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	result, err := db.Query(query)
+	if err != nil {
+		return err
+	}
+
+	if isStructuredOutput() {
+		dataset := output.Dataset{Columns: result.Columns, Rows: result.Rows}
+		return renderOutput(dataset, dataset)
+	}
+
+	if len(result.Rows) == 0 {
+		fmt.Println("No rows")
+		return nil
+	}
+
+	var rows [][]string
+	for _, row := range result.Rows {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			if v != nil {
+				cells[i] = fmt.Sprint(v)
+			}
+		}
+		rows = append(rows, cells)
+	}
+
+	output.Table(result.Columns, rows)
+	return nil
+}
+
+func reportFilter() store.Filter {
+	return store.Filter{
+		SiteReference: dbReportSite,
+		From:          dbReportFrom,
+		To:            dbReportTo,
+	}
+}
+
+func runDBReportMonthlyCosts(cmd *cobra.Command, args []string) error {
+	db, err := openHistory()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	costs, err := db.MonthlyCosts(reportFilter())
+	if err != nil {
+		return err
+	}
+
+	if isStructuredOutput() {
+		return renderOutput(costs, costs)
+	}
+
+	if len(costs) == 0 {
+		fmt.Println("No usage history available")
+		return nil
+	}
+
+	headers := []string{"Month", "Site", "Electricity", "Costs", "Gas", "Costs", "Feed-in", "Costs", "Total"}
+	var rows [][]string
+	total := 0.0
+
+	for _, c := range costs {
+		rows = append(rows, []string{
+			c.Month,
+			c.SiteReference,
+			fmt.Sprintf("%.2f kWh", c.ElectricityUsage),
+			fmt.Sprintf("€%.2f", c.ElectricityCosts),
+			fmt.Sprintf("%.2f m³", c.GasUsage),
+			fmt.Sprintf("€%.2f", c.GasCosts),
+			fmt.Sprintf("%.2f kWh", c.FeedInUsage),
+			fmt.Sprintf("€%.2f", c.FeedInCosts),
+			fmt.Sprintf("€%.2f", c.TotalCosts),
+		})
+		total += c.TotalCosts
+	}
+
+	output.Table(headers, rows)
+	fmt.Printf("Total: €%.2f\n", total)
+	return nil
+}
+
+func runDBReportPriceHistogram(cmd *cobra.Command, args []string) error {
+	if dbReportSegment != "electricity" && dbReportSegment != "gas" {
+		return fmt.Errorf("invalid segment: %s (must be electricity or gas)", dbReportSegment)
+	}
+
+	db, err := openHistory()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	buckets, err := db.PriceHistogram(dbReportSegment, reportFilter(), dbReportBucket)
+	if err != nil {
+		return err
+	}
+
+	if isStructuredOutput() {
+		return renderOutput(buckets, buckets)
+	}
+
+	if len(buckets) == 0 {
+		fmt.Println("No price history available")
+		return nil
+	}
+
+	maxCount := 0
+	for _, b := range buckets {
+		if b.Count > maxCount {
+			maxCount = b.Count
+		}
+	}
+
+	headers := []string{"All-In", "Count", "Share", ""}
+	var rows [][]string
+
+	for _, b := range buckets {
+		bar := strings.Repeat("█", b.Count*histogramBarWidth/maxCount)
+		rows = append(rows, []string{
+			fmt.Sprintf("€%.4f - €%.4f", b.From, b.Till),
+			fmt.Sprintf("%d", b.Count),
+			fmt.Sprintf("%.1f%%", b.Share*100),
+			bar,
+		})
+	}
+
+	fmt.Printf("%s price distribution\n", strings.ToUpper(dbReportSegment[:1])+dbReportSegment[1:])
+	output.Table(headers, rows)
+	return nil
+}
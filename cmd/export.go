@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pietern/frankie/internal/api"
+	"github.com/pietern/frankie/internal/config"
+	"github.com/pietern/frankie/internal/models"
+	"github.com/pietern/frankie/internal/output"
+)
+
+const datasetChargeStates = "charge-states"
+
+var allExportDatasets = []string{
+	datasetPrices,
+	datasetUsage,
+	datasetBatterySessions,
+	datasetChargeStates,
+}
+
+var (
+	exportFrom       string
+	exportTo         string
+	exportSite       string
+	exportDatasets   []string
+	exportResolution int
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export data for other tools",
+}
+
+var exportInfluxCmd = &cobra.Command{
+	Use:   "influx",
+	Short: "Export data as InfluxDB line protocol",
+	Long: `Export price intervals, usage items, battery sessions and current charge
+states as InfluxDB line protocol with nanosecond timestamps.
+
+Measurements and tags:
+  price            segment, siteReference, resolution, unit
+  usage            segment, siteReference, ean, unit
+  battery_session  deviceId
+  charge_state     kind, deviceId, brand, model
+
+Prices are public market prices, or the customer prices of a site when
+--site is given. Points are written as soon as each day is fetched.
+
+Examples:
+  frankie export influx --from 2025-01-01 | influx write --bucket energy
+  frankie export influx --datasets usage --site 1234AB > usage.lp`,
+	RunE: runExportInflux,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportInfluxCmd)
+
+	exportInfluxCmd.Flags().StringVar(&exportFrom, "from", "", "first date to export (YYYY-MM-DD, default: yesterday)")
+	exportInfluxCmd.Flags().StringVar(&exportTo, "to", "", "last date to export (YYYY-MM-DD, default: today)")
+	exportInfluxCmd.Flags().StringVarP(&exportSite, "site", "s", "", "site reference (default: all sites, and public prices)")
+	bindSiteFlag(exportInfluxCmd, "site")
+	bindDateFlag(exportInfluxCmd, exportInfluxCmd.Flags(), "from")
+	bindDateFlag(exportInfluxCmd, exportInfluxCmd.Flags(), "to")
+	exportInfluxCmd.Flags().StringSliceVar(&exportDatasets, "datasets", allExportDatasets, "datasets to export")
+	exportInfluxCmd.Flags().IntVarP(&exportResolution, "resolution", "r", resolution60Min, "public price resolution in minutes (15 or 60)")
+	bindSetting(exportInfluxCmd.Flags(), "resolution", config.SettingResolution)
+}
+
+// exporter writes datasets as line protocol
+type exporter struct {
+	client     *api.Client
+	w          io.Writer
+	dates      []string
+	resolution string
+	siteRef    string
+}
+
+func runExportInflux(cmd *cobra.Command, args []string) error {
+	for _, dataset := range exportDatasets {
+		if !isExportDataset(dataset) {
+			return fmt.Errorf("unknown dataset: %s (must be one of %s)", dataset, strings.Join(allExportDatasets, ", "))
+		}
+	}
+
+	from := exportFrom
+	if from == "" {
+		from = time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	}
+	to := exportTo
+	if to == "" {
+		to = time.Now().Format("2006-01-02")
+	}
+	dates, err := datesBetween(from, to)
+	if err != nil {
+		return err
+	}
+
+	resolution, err := priceResolution(exportResolution)
+	if err != nil {
+		return err
+	}
+
+	// Public prices are the only data that doesn't require login
+	client := api.NewClient()
+	if exportSite != "" || resolution == resolutionPT15M || len(exportDatasets) != 1 || exportDatasets[0] != datasetPrices {
+		client, err = newAuthenticatedClient()
+		if err != nil {
+			return err
+		}
+	}
+
+	e := &exporter{
+		client:     client,
+		w:          os.Stdout,
+		dates:      dates,
+		resolution: resolution,
+	}
+	if exportSite != "" {
+		e.siteRef, err = resolveSiteReference(client, exportSite)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, dataset := range exportDatasets {
+		var err error
+		switch dataset {
+		case datasetPrices:
+			err = e.exportPrices()
+		case datasetUsage:
+			err = e.exportUsage()
+		case datasetBatterySessions:
+			err = e.exportBatterySessions()
+		case datasetChargeStates:
+			err = e.exportChargeStates()
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isExportDataset(name string) bool {
+	for _, d := range allExportDatasets {
+		if d == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *exporter) exportPrices() error {
+	for _, date := range e.dates {
+		var prices *models.MarketPrices
+		var err error
+		if e.siteRef != "" {
+			prices, err = fetchCustomerPrices(e.client, date, e.siteRef)
+		} else {
+			prices, err = fetchPublicPrices(e.client, date, e.resolution)
+		}
+		if err != nil {
+			return err
+		}
+		if prices == nil {
+			continue
+		}
+
+		records := append(priceRecords(prices, false, e.siteRef), priceRecords(prices, true, e.siteRef)...)
+		if err := output.LineProtocol(e.w, records); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *exporter) exportUsage() error {
+	sites, err := fetchSites(e.client)
+	if err != nil {
+		return err
+	}
+
+	for _, site := range sites {
+		if e.siteRef != "" && site.Reference != e.siteRef {
+			continue
+		}
+
+		source, err := newUsageSource(e.client, site.Reference)
+		if err != nil {
+			return err
+		}
+
+		for _, date := range e.dates {
+			usage, err := fetchUsage(e.client, site.Reference, date)
+			if err != nil {
+				return err
+			}
+			if usage == nil {
+				continue
+			}
+			if err := output.LineProtocol(e.w, usageRecords(usage, "", source)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *exporter) exportBatterySessions() error {
+	batteries, err := fetchBatteries(e.client)
+	if err != nil {
+		if errors.Is(err, api.ErrSmartTradingNotEnabled) {
+			return nil
+		}
+		return err
+	}
+
+	first, last := e.dates[0], e.dates[len(e.dates)-1]
+	for _, battery := range batteries {
+		for chunkStart := first; chunkStart <= last; {
+			t, _ := time.Parse("2006-01-02", chunkStart)
+			chunkEnd := t.AddDate(0, 0, batterySessionChunkDays-1).Format("2006-01-02")
+			if chunkEnd > last {
+				chunkEnd = last
+			}
+
+			sessions, err := fetchBatterySessions(e.client, battery.ID, chunkStart, chunkEnd)
+			if err != nil {
+				return err
+			}
+			if sessions != nil {
+				if err := output.LineProtocol(e.w, batterySessionRecords(sessions)); err != nil {
+					return err
+				}
+			}
+
+			chunkStart = t.AddDate(0, 0, batterySessionChunkDays).Format("2006-01-02")
+		}
+	}
+	return nil
+}
+
+// exportChargeStates writes the current charge state of all chargers and vehicles
+func (e *exporter) exportChargeStates() error {
+	chargers, err := fetchChargers(e.client)
+	if err != nil && !errors.Is(err, api.ErrSmartChargingNotEnabled) {
+		return err
+	}
+	if err := output.LineProtocol(e.w, chargerRecords(chargers)); err != nil {
+		return err
+	}
+
+	vehicles, err := fetchVehicles(e.client)
+	if err != nil && !errors.Is(err, api.ErrSmartChargingNotEnabled) {
+		return err
+	}
+	return output.LineProtocol(e.w, vehicleRecords(vehicles))
+}
@@ -2,6 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/huh"
+	"github.com/mattn/go-isatty"
 
 	"github.com/pietern/frankie/internal/api"
 	"github.com/pietern/frankie/internal/auth"
@@ -16,3 +22,69 @@ func newAuthenticatedClient() (*api.Client, error) {
 	}
 	return client, nil
 }
+
+// datesBetween returns all dates from start to end (inclusive) as YYYY-MM-DD strings.
+func datesBetween(start, end string) ([]string, error) {
+	from, err := time.Parse("2006-01-02", start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date '%s' (expected YYYY-MM-DD)", start)
+	}
+	till, err := time.Parse("2006-01-02", end)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date '%s' (expected YYYY-MM-DD)", end)
+	}
+	if till.Before(from) {
+		return nil, fmt.Errorf("end date %s is before start date %s", end, start)
+	}
+
+	var dates []string
+	for d := from; !d.After(till); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d.Format("2006-01-02"))
+	}
+	return dates, nil
+}
+
+// relativeDateNames are the relative dates accepted by date flags
+var relativeDateNames = []string{"today", "tomorrow", "yesterday"}
+
+// resolveRelativeDate returns the YYYY-MM-DD date of a relative date such as
+// today or tomorrow. ok is false for any other value.
+func resolveRelativeDate(value string) (date string, ok bool) {
+	offset := map[string]int{"today": 0, "tomorrow": 1, "yesterday": -1}
+	days, ok := offset[strings.ToLower(value)]
+	if !ok {
+		return "", false
+	}
+	return time.Now().AddDate(0, 0, days).Format("2006-01-02"), true
+}
+
+// parseTimestamp parses an API timestamp (RFC 3339) or date (YYYY-MM-DD,
+// in market time). It returns the zero time if the value cannot be parsed.
+func parseTimestamp(value string) time.Time {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t
+	}
+	if t, err := time.ParseInLocation("2006-01-02", formatDate(value), marketLocation()); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// promptPassphrase asks for the passphrase of encrypted credentials
+func promptPassphrase() (string, error) {
+	var passphrase string
+	input := huh.NewInput().
+		Title("Credentials passphrase").
+		Value(&passphrase).
+		EchoMode(huh.EchoModePassword)
+	// Drawn on stderr, so it doesn't end up in redirected output
+	if err := huh.NewForm(huh.NewGroup(input)).WithOutput(os.Stderr).Run(); err != nil {
+		return "", fmt.Errorf("passphrase prompt cancelled: %w", err)
+	}
+	return passphrase, nil
+}
@@ -7,13 +7,15 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/pietern/frankie/internal/api"
+	"github.com/pietern/frankie/internal/config"
 	"github.com/pietern/frankie/internal/models"
 	"github.com/pietern/frankie/internal/output"
 )
 
 var (
-	invoicesSite string
-	invoicesAll  bool
+	invoicesSite     string
+	invoicesAll      bool
+	invoicesAllSites bool
 )
 
 var invoicesCmd = &cobra.Command{
@@ -26,7 +28,11 @@ var invoicesCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(invoicesCmd)
 	invoicesCmd.Flags().StringVarP(&invoicesSite, "site", "s", "", "site reference (optional if you have one site)")
+	bindSetting(invoicesCmd.Flags(), "site", config.SettingSite)
 	invoicesCmd.Flags().BoolVarP(&invoicesAll, "all", "a", false, "show all invoices")
+	invoicesCmd.Flags().BoolVar(&invoicesAllSites, "all-sites", false, "show every active site and their total")
+	invoicesCmd.MarkFlagsMutuallyExclusive("site", "all-sites")
+	bindSiteFlag(invoicesCmd, "site")
 }
 
 func runInvoices(cmd *cobra.Command, args []string) error {
@@ -35,34 +41,27 @@ func runInvoices(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if invoicesAllSites {
+		return runInvoicesAllSites(client)
+	}
+
 	// Resolve site reference
 	siteRef, err := resolveSiteReference(client, invoicesSite)
 	if err != nil {
 		return err
 	}
 
-	variables := map[string]interface{}{
-		"siteReference": siteRef,
-	}
-
-	resp, err := client.Execute(api.InvoicesQuery, "Invoices", variables)
+	invoices, err := fetchInvoices(client, siteRef)
 	if err != nil {
-		return fmt.Errorf("failed to fetch invoices: %w", err)
-	}
-
-	var result models.InvoicesResponse
-	if err := json.Unmarshal(resp.Data, &result); err != nil {
-		return fmt.Errorf("failed to parse response: %w", err)
+		return err
 	}
-
-	invoices := result.Invoices
 	if invoices == nil {
 		fmt.Println("No invoice data available")
 		return nil
 	}
 
-	if getOutputFormat() == "json" {
-		return output.JSON(invoices)
+	if isStructuredOutput() {
+		return renderOutput(invoices, invoices.AllInvoices)
 	}
 
 	if invoicesAll && len(invoices.AllInvoices) > 0 {
@@ -74,6 +73,146 @@ func runInvoices(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// SiteInvoices are the invoices of one site
+type SiteInvoices struct {
+	SiteReference string `json:"siteReference"`
+	*models.Invoices
+}
+
+// InvoiceRecord is a single invoice of one of several sites
+type InvoiceRecord struct {
+	SiteReference string `json:"siteReference"`
+	models.Invoice
+}
+
+// InvoicesTotal is the sum of the period invoices of all sites
+type InvoicesTotal struct {
+	PreviousPeriod float64 `json:"previousPeriod"`
+	CurrentPeriod  float64 `json:"currentPeriod"`
+	UpcomingPeriod float64 `json:"upcomingPeriod"`
+	AllInvoices    float64 `json:"allInvoices"`
+}
+
+// AllSitesInvoices are the invoices of every site
+type AllSitesInvoices struct {
+	Sites []SiteInvoices `json:"sites"`
+	Total InvoicesTotal  `json:"total"`
+}
+
+// runInvoicesAllSites shows the invoices of every site, one row per site (or
+// per invoice with --all)
+func runInvoicesAllSites(client *api.Client) error {
+	sites, err := fetchSites(client)
+	if err != nil {
+		return err
+	}
+	sites = siteFilter{}.apply(sites)
+
+	all := make([]*models.Invoices, len(sites))
+	err = forEachSite(sites, func(i int, site models.Site) error {
+		invoices, err := fetchInvoices(client, site.Reference)
+		all[i] = invoices
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	var result AllSitesInvoices
+	var records []InvoiceRecord
+	for i, site := range sites {
+		invoices := all[i]
+		result.Sites = append(result.Sites, SiteInvoices{SiteReference: site.Reference, Invoices: invoices})
+		if invoices == nil {
+			continue
+		}
+		result.Total.PreviousPeriod += invoiceAmount(invoices.PreviousPeriodInvoice)
+		result.Total.CurrentPeriod += invoiceAmount(invoices.CurrentPeriodInvoice)
+		result.Total.UpcomingPeriod += invoiceAmount(invoices.UpcomingPeriodInvoice)
+		for _, inv := range invoices.AllInvoices {
+			result.Total.AllInvoices += inv.TotalAmount
+			records = append(records, InvoiceRecord{SiteReference: site.Reference, Invoice: inv})
+		}
+	}
+
+	if isStructuredOutput() {
+		return renderOutput(result, records)
+	}
+
+	if invoicesAll {
+		headers := []string{"Site", "Period", "Date", "Amount"}
+		var rows [][]string
+		for _, r := range records {
+			rows = append(rows, []string{
+				r.SiteReference,
+				r.PeriodDescription,
+				formatDate(r.StartDate),
+				fmt.Sprintf("€%.2f", r.TotalAmount),
+			})
+		}
+		rows = append(rows, []string{"Total", "", "", fmt.Sprintf("€%.2f", result.Total.AllInvoices)})
+		output.Table(headers, rows)
+		return nil
+	}
+
+	headers := []string{"Site", "Previous", "Current", "Upcoming"}
+	var rows [][]string
+	for _, s := range result.Sites {
+		if s.Invoices == nil {
+			rows = append(rows, []string{s.SiteReference, "-", "-", "-"})
+			continue
+		}
+		rows = append(rows, []string{
+			s.SiteReference,
+			formatInvoice(s.PreviousPeriodInvoice),
+			formatInvoice(s.CurrentPeriodInvoice),
+			formatInvoice(s.UpcomingPeriodInvoice),
+		})
+	}
+	rows = append(rows, []string{
+		"Total",
+		fmt.Sprintf("€%.2f", result.Total.PreviousPeriod),
+		fmt.Sprintf("€%.2f", result.Total.CurrentPeriod),
+		fmt.Sprintf("€%.2f", result.Total.UpcomingPeriod),
+	})
+	output.Table(headers, rows)
+	return nil
+}
+
+// invoiceAmount returns the amount of an invoice, or 0 if there is none
+func invoiceAmount(inv *models.Invoice) float64 {
+	if inv == nil {
+		return 0
+	}
+	return inv.TotalAmount
+}
+
+// formatInvoice formats the period and amount of an invoice
+func formatInvoice(inv *models.Invoice) string {
+	if inv == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%s - €%.2f", inv.PeriodDescription, inv.TotalAmount)
+}
+
+func fetchInvoices(client *api.Client, siteRef string) (*models.Invoices, error) {
+	variables := map[string]interface{}{
+		"siteReference": siteRef,
+	}
+
+	resp, err := client.Execute(api.InvoicesQuery, "Invoices", variables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch invoices: %w", err)
+	}
+
+	var result models.InvoicesResponse
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result.Invoices, nil
+}
+
 func displayInvoiceSummary(invoices *models.Invoices) {
 	fmt.Println("Invoice Summary")
 	fmt.Println()
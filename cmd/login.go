@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/charmbracelet/huh"
 	"github.com/spf13/cobra"
@@ -11,43 +14,96 @@ import (
 	"github.com/pietern/frankie/internal/auth"
 )
 
+// maxSecretSize limits how much is read from stdin or a password file
+const maxSecretSize = 64 * 1024
+
 var (
-	loginEmail    string
-	loginPassword string
+	loginEmail             string
+	loginPassword          string
+	loginPasswordStdin     bool
+	loginPasswordFile      string
+	loginRefreshTokenStdin bool
 )
 
 var loginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Authenticate with Frank Energie",
-	Long:  `Login to Frank Energie with your email and password.`,
-	RunE:  runLogin,
+	Long: `Login to Frank Energie with your email and password.
+
+The email is taken from --email or $FRANKIE_EMAIL, and the password from
+--password-stdin, --password-file or $FRANKIE_PASSWORD. Missing values are
+asked for when running in a terminal.
+
+With --refresh-token-stdin, a refresh token provisioned elsewhere is
+exchanged for a new login, so the password is never needed. The refresh
+token may be followed by the auth token it was issued with on a second line.
+
+Examples:
+  frankie login
+  pass show frank | frankie login --email me@example.com --password-stdin
+  frankie login --refresh-token-stdin < /run/secrets/frank-refresh-token`,
+	RunE: runLogin,
 }
 
 func init() {
 	rootCmd.AddCommand(loginCmd)
-	loginCmd.Flags().StringVarP(&loginEmail, "email", "e", "", "email address")
-	loginCmd.Flags().StringVarP(&loginPassword, "password", "p", "", "password")
+	loginCmd.Flags().StringVarP(&loginEmail, "email", "e", "", "email address (default: $FRANKIE_EMAIL)")
+	loginCmd.Flags().StringVarP(&loginPassword, "password", "p", "", "password (visible in process lists; prefer --password-stdin)")
+	loginCmd.Flags().BoolVar(&loginPasswordStdin, "password-stdin", false, "read the password from stdin")
+	loginCmd.Flags().StringVar(&loginPasswordFile, "password-file", "", "read the password from a file")
+	loginCmd.Flags().BoolVar(&loginRefreshTokenStdin, "refresh-token-stdin", false, "log in with a refresh token read from stdin")
+	loginCmd.MarkFlagsMutuallyExclusive("password", "password-stdin", "password-file", "refresh-token-stdin")
+	loginCmd.MarkFlagsMutuallyExclusive("email", "refresh-token-stdin")
 }
 
 func runLogin(cmd *cobra.Command, args []string) error {
+	client := api.NewClient()
+	manager := auth.NewManager(client)
+
+	if loginRefreshTokenStdin {
+		refreshToken, authToken, err := readRefreshToken(os.Stdin)
+		if err != nil {
+			return err
+		}
+		if err := manager.LoginWithRefreshToken(refreshToken, authToken); err != nil {
+			fmt.Fprintln(os.Stderr, "Login failed:", err)
+			return err
+		}
+		fmt.Println("Login successful!")
+		return nil
+	}
+
 	email := loginEmail
-	password := loginPassword
+	if email == "" {
+		email = os.Getenv("FRANKIE_EMAIL")
+	}
+
+	password, err := loginPasswordInput()
+	if err != nil {
+		return err
+	}
 
-	// If credentials not provided via flags, use interactive form
+	// Ask for missing credentials when running interactively
 	if email == "" || password == "" {
-		form := huh.NewForm(
-			huh.NewGroup(
-				huh.NewInput().
-					Title("Email").
-					Value(&email).
-					Placeholder("your@email.com"),
-				huh.NewInput().
-					Title("Password").
-					Value(&password).
-					EchoMode(huh.EchoModePassword),
-			),
-		)
+		if !isTerminal(os.Stdin) {
+			return fmt.Errorf("email and password are required (use --email or $FRANKIE_EMAIL with --password-stdin, --password-file or $FRANKIE_PASSWORD)")
+		}
+
+		var fields []huh.Field
+		if email == "" {
+			fields = append(fields, huh.NewInput().
+				Title("Email").
+				Value(&email).
+				Placeholder("your@email.com"))
+		}
+		if password == "" {
+			fields = append(fields, huh.NewInput().
+				Title("Password").
+				Value(&password).
+				EchoMode(huh.EchoModePassword))
+		}
 
+		form := huh.NewForm(huh.NewGroup(fields...))
 		if err := form.Run(); err != nil {
 			return fmt.Errorf("form cancelled: %w", err)
 		}
@@ -57,10 +113,7 @@ func runLogin(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("email and password are required")
 	}
 
-	client := api.NewClient()
-	manager := auth.NewManager(client)
-
-	err := manager.Login(email, password)
+	err = manager.Login(email, password)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Login failed:", err)
 		return err
@@ -70,3 +123,59 @@ func runLogin(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// loginPasswordInput returns the password from the flags or environment, or "" if none is given
+func loginPasswordInput() (string, error) {
+	switch {
+	case loginPasswordStdin:
+		return readSecret(os.Stdin, "stdin")
+	case loginPasswordFile != "":
+		f, err := os.Open(loginPasswordFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password file: %w", err)
+		}
+		defer f.Close()
+		return readSecret(f, loginPasswordFile)
+	case loginPassword != "":
+		return loginPassword, nil
+	}
+	return os.Getenv("FRANKIE_PASSWORD"), nil
+}
+
+// readSecret reads a secret, without its trailing newline
+func readSecret(r io.Reader, name string) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxSecretSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	secret := strings.TrimRight(string(data), "\r\n")
+	if secret == "" {
+		return "", fmt.Errorf("no password in %s", name)
+	}
+	return secret, nil
+}
+
+// readRefreshToken reads a refresh token and an optional auth token, one per line
+func readRefreshToken(r io.Reader) (refreshToken, authToken string, err error) {
+	var lines []string
+	scanner := bufio.NewScanner(io.LimitReader(r, maxSecretSize))
+	scanner.Buffer(make([]byte, 0, 4096), maxSecretSize)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", "", fmt.Errorf("failed to read stdin: %w", err)
+	}
+
+	switch len(lines) {
+	case 1:
+		return lines[0], "", nil
+	case 2:
+		return lines[0], lines[1], nil
+	case 0:
+		return "", "", fmt.Errorf("no refresh token in stdin")
+	}
+	return "", "", fmt.Errorf("expected a refresh token and optionally an auth token on stdin, got %d lines", len(lines))
+}
@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 
 	"github.com/pietern/frankie/internal/auth"
+	"github.com/pietern/frankie/internal/config"
 )
 
 var logoutCmd = &cobra.Command{
@@ -29,6 +31,9 @@ func runLogout(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to logout: %w", err)
 	}
 
+	// Sites and devices cached for shell completion belong to the account
+	os.RemoveAll(completionCacheDir(config.GetProfile()))
+
 	fmt.Println("Logged out successfully")
 	return nil
 }
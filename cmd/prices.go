@@ -3,6 +3,7 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 
 	"github.com/pietern/frankie/internal/api"
 	"github.com/pietern/frankie/internal/auth"
+	"github.com/pietern/frankie/internal/config"
 	"github.com/pietern/frankie/internal/models"
 	"github.com/pietern/frankie/internal/output"
 )
@@ -30,6 +32,9 @@ var (
 	pricesSite       string
 	pricesShowGas    bool
 	pricesResolution int
+	pricesFrom       string
+	pricesTo         string
+	pricesAllSites   bool
 )
 
 var pricesCmd = &cobra.Command{
@@ -45,14 +50,48 @@ func init() {
 	pricesCmd.Flags().BoolVar(&pricesBelgium, "be", false, "show Belgium prices instead of Netherlands")
 	pricesCmd.Flags().StringVarP(&pricesSite, "site", "s", "", "site reference for customer-specific prices")
 	pricesCmd.Flags().BoolVar(&pricesShowGas, "gas", false, "show gas prices instead of electricity")
+	pricesCmd.Flags().StringVar(&pricesFrom, "from", "", "first date of a range to show prices for (YYYY-MM-DD)")
+	pricesCmd.Flags().StringVar(&pricesTo, "to", "", "last date of a range (YYYY-MM-DD, default: latest published)")
+	pricesCmd.MarkFlagsMutuallyExclusive("date", "from")
 	pricesCmd.Flags().IntVarP(&pricesResolution, "resolution", "r", resolution60Min, "price resolution in minutes (15 or 60, 15 requires login)")
+	bindSetting(pricesCmd.Flags(), "site", config.SettingSite)
+	bindSetting(pricesCmd.Flags(), "resolution", config.SettingResolution)
+	bindCountrySetting(pricesCmd.Flags(), "be")
+	pricesCmd.Flags().BoolVar(&pricesAllSites, "all-sites", false, "show customer-specific prices of every site")
+	pricesCmd.MarkFlagsMutuallyExclusive("site", "all-sites")
+	pricesCmd.MarkFlagsMutuallyExclusive("be", "all-sites")
+	bindSiteFlag(pricesCmd, "site")
+	bindDateFlag(pricesCmd, pricesCmd.Flags(), "date")
+	bindDateFlag(pricesCmd, pricesCmd.Flags(), "from")
+	bindDateFlag(pricesCmd, pricesCmd.Flags(), "to")
 }
 
 func runPrices(cmd *cobra.Command, args []string) error {
 	client := api.NewClient()
 
 	// Determine dates to fetch
-	dates := getPriceDates()
+	dates, err := getPriceDates()
+	if err != nil {
+		return err
+	}
+
+	if pricesAllSites {
+		return runPricesAllSites(client, dates)
+	}
+
+	// Public Belgium prices were asked for, not those of the default site
+	if cmd.Flags().Changed("be") && !cmd.Flags().Changed("site") {
+		pricesSite = ""
+	}
+
+	// Resolve partial site reference (only once)
+	var siteRef string
+	if pricesSite != "" {
+		siteRef, err = resolveSiteReference(client, pricesSite)
+		if err != nil {
+			return err
+		}
+	}
 
 	// Collect all prices
 	type priceResult struct {
@@ -60,19 +99,14 @@ func runPrices(cmd *cobra.Command, args []string) error {
 		prices *models.MarketPrices
 	}
 	var results []priceResult
+	streamed := 0
 
 	for _, date := range dates {
 		var prices *models.MarketPrices
 		var err error
 
-		if pricesSite != "" {
+		if siteRef != "" {
 			// Customer-specific prices (requires auth)
-			// Resolve partial site reference (only once)
-			var siteRef string
-			siteRef, err = resolveSiteReference(client, pricesSite)
-			if err != nil {
-				return err
-			}
 			prices, err = fetchCustomerPrices(client, date, siteRef)
 		} else if pricesBelgium {
 			// Belgium prices
@@ -101,26 +135,28 @@ func runPrices(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
-		if prices != nil {
-			results = append(results, priceResult{date: date, prices: prices})
+		if prices == nil {
+			continue
+		}
+
+		// Stream each day as soon as it is fetched
+		if isStreamingOutput() {
+			if err := streamRecords(priceRecords(prices, pricesShowGas, siteRef)); err != nil {
+				return err
+			}
+			streamed++
+			continue
 		}
+
+		results = append(results, priceResult{date: date, prices: prices})
 	}
 
-	if len(results) == 0 {
-		return fmt.Errorf("no prices available")
+	if streamed > 0 {
+		return nil
 	}
 
-	if getOutputFormat() == "json" {
-		if len(results) == 1 {
-			return output.JSON(results[0].prices)
-		}
-		// Multiple dates: merge prices into single response
-		merged := &models.MarketPrices{}
-		for _, r := range results {
-			merged.ElectricityPrices = append(merged.ElectricityPrices, r.prices.ElectricityPrices...)
-			merged.GasPrices = append(merged.GasPrices, r.prices.GasPrices...)
-		}
-		return output.JSON(merged)
+	if len(results) == 0 {
+		return fmt.Errorf("no prices available")
 	}
 
 	// Merge all prices
@@ -130,6 +166,14 @@ func runPrices(cmd *cobra.Command, args []string) error {
 		allPrices.GasPrices = append(allPrices.GasPrices, r.prices.GasPrices...)
 	}
 
+	if isStructuredOutput() {
+		doc := &allPrices
+		if len(results) == 1 {
+			doc = results[0].prices
+		}
+		return renderOutput(doc, priceRecords(&allPrices, pricesShowGas, siteRef))
+	}
+
 	// Display prices
 	if pricesShowGas {
 		return displayPrices("Gas", allPrices.GasPrices)
@@ -137,30 +181,187 @@ func runPrices(cmd *cobra.Command, args []string) error {
 	return displayPrices("Electricity", allPrices.ElectricityPrices)
 }
 
+// SitePrices are the customer-specific prices of one site
+type SitePrices struct {
+	SiteReference string `json:"siteReference"`
+	*models.MarketPrices
+}
+
+// runPricesAllSites shows the customer-specific prices of every site, with
+// one all-in price column per site
+func runPricesAllSites(client *api.Client, dates []string) error {
+	manager := auth.NewManager(client)
+	if err := manager.EnsureAuthenticated(); err != nil {
+		return fmt.Errorf("not logged in: %w", err)
+	}
+	sites, err := fetchSites(client)
+	if err != nil {
+		return err
+	}
+
+	sites = siteFilter{}.apply(sites)
+
+	all := make([]SitePrices, len(sites))
+	err = forEachSite(sites, func(i int, site models.Site) error {
+		merged := &models.MarketPrices{}
+		for _, date := range dates {
+			prices, err := fetchCustomerPrices(client, date, site.Reference)
+			if err != nil {
+				return err
+			}
+			if prices == nil {
+				continue
+			}
+			merged.ElectricityPrices = append(merged.ElectricityPrices, prices.ElectricityPrices...)
+			merged.GasPrices = append(merged.GasPrices, prices.GasPrices...)
+		}
+		all[i] = SitePrices{SiteReference: site.Reference, MarketPrices: merged}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var records []PriceRecord
+	for _, sp := range all {
+		records = append(records, priceRecords(sp.MarketPrices, pricesShowGas, sp.SiteReference)...)
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("no prices available")
+	}
+
+	if isStructuredOutput() {
+		return renderOutput(all, records)
+	}
+
+	label := "Electricity"
+	if pricesShowGas {
+		label = "Gas"
+	}
+	fmt.Printf("%s all-in prices\n", label)
+
+	// Join the prices of all sites by interval
+	headers := []string{"Date", "Time"}
+	var times []time.Time
+	byTime := map[time.Time][]string{}
+	for i, sp := range all {
+		headers = append(headers, sp.SiteReference)
+		for _, r := range priceRecords(sp.MarketPrices, pricesShowGas, sp.SiteReference) {
+			if _, ok := byTime[r.From]; !ok {
+				times = append(times, r.From)
+				byTime[r.From] = make([]string, len(all))
+			}
+			byTime[r.From][i] = fmt.Sprintf("€%.4f", r.AllInPrice)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	var rows [][]string
+	for _, t := range times {
+		row := []string{t.Local().Format("2006-01-02"), t.Local().Format("15:04")}
+		rows = append(rows, append(row, byTime[t]...))
+	}
+	output.Table(headers, rows)
+	return nil
+}
+
+// PriceRecord is a single price interval in CSV/TSV output
+type PriceRecord struct {
+	Segment       string `json:"segment"`
+	SiteReference string `json:"siteReference"`
+	models.Price
+}
+
+// InfluxPoint returns the price interval as a line protocol point
+func (r PriceRecord) InfluxPoint() output.Point {
+	return output.Point{
+		Measurement: "price",
+		Tags: []output.Tag{
+			{Key: "segment", Value: r.Segment},
+			{Key: "siteReference", Value: r.SiteReference},
+			{Key: "resolution", Value: r.Resolution},
+			{Key: "unit", Value: r.PerUnit},
+		},
+		Fields: []output.Field{
+			{Key: "marketPrice", Value: r.MarketPrice},
+			{Key: "marketPriceTax", Value: r.MarketPriceTax},
+			{Key: "sourcingMarkupPrice", Value: r.SourcingMarkupPrice},
+			{Key: "energyTaxPrice", Value: r.EnergyTaxPrice},
+			{Key: "marketPricePlus", Value: r.MarketPricePlus},
+			{Key: "allInPrice", Value: r.AllInPrice},
+		},
+		Time: r.From,
+	}
+}
+
+// priceRecords returns the electricity (or gas) price intervals as records.
+// The site reference is empty for public market prices.
+func priceRecords(prices *models.MarketPrices, gas bool, siteRef string) []PriceRecord {
+	segment, list := "electricity", prices.ElectricityPrices
+	if gas {
+		segment, list = "gas", prices.GasPrices
+	}
+
+	records := make([]PriceRecord, 0, len(list))
+	for _, p := range list {
+		records = append(records, PriceRecord{Segment: segment, SiteReference: siteRef, Price: p})
+	}
+	return records
+}
+
+// priceResolution converts a resolution in minutes to its API name
+func priceResolution(minutes int) (string, error) {
+	switch minutes {
+	case resolution15Min:
+		return resolutionPT15M, nil
+	case resolution60Min:
+		return resolutionPT60M, nil
+	}
+	return "", fmt.Errorf("invalid resolution: %d (must be %d or %d)", minutes, resolution15Min, resolution60Min)
+}
+
 // getPriceDates returns the dates to fetch prices for.
-// If a specific date was requested, returns only that date.
+// If a specific date or range was requested, returns only those dates.
 // Otherwise returns today, and tomorrow if after 13:00 CET.
-func getPriceDates() []string {
+func getPriceDates() ([]string, error) {
+	if pricesFrom != "" {
+		to := pricesTo
+		if to == "" {
+			to = latestPriceDate()
+		}
+		return datesBetween(pricesFrom, to)
+	}
+
 	if pricesDate != "" {
-		return []string{pricesDate}
+		return []string{pricesDate}, nil
+	}
+
+	now := time.Now().In(marketLocation())
+	today := now.Format("2006-01-02")
+	if latest := latestPriceDate(); latest != today {
+		return []string{today, latest}, nil
 	}
+	return []string{today}, nil
+}
 
-	// Load CET timezone
+// marketLocation returns the timezone of the day-ahead market (CET)
+func marketLocation() *time.Location {
 	cet, err := time.LoadLocation("Europe/Amsterdam")
 	if err != nil {
 		// Fallback to local time if timezone fails
-		cet = time.Local
+		return time.Local
 	}
+	return cet
+}
 
-	now := time.Now().In(cet)
-	today := now.Format("2006-01-02")
-	tomorrow := now.AddDate(0, 0, 1).Format("2006-01-02")
-
-	// Day-ahead prices are published around 13:00 CET
+// latestPriceDate returns the last date for which prices have been published.
+// Day-ahead prices are published around 13:00 CET.
+func latestPriceDate() string {
+	now := time.Now().In(marketLocation())
 	if now.Hour() >= tomorrowPricesAvailableHour {
-		return []string{today, tomorrow}
+		return now.AddDate(0, 0, 1).Format("2006-01-02")
 	}
-	return []string{today}
+	return now.Format("2006-01-02")
 }
 
 func fetchPublicPrices(client *api.Client, date, resolution string) (*models.MarketPrices, error) {
@@ -250,62 +451,3 @@ func displayPrices(label string, prices []models.Price) error {
 
 	return nil
 }
-
-// resolveSiteReference resolves a partial site reference to the full reference
-// It supports matching by:
-// - Full reference (exact match)
-// - Postal code (e.g., "8147RJ")
-// - Postal code with house number (e.g., "8147RJ 26")
-func resolveSiteReference(client *api.Client, partial string) (string, error) {
-	manager := auth.NewManager(client)
-	if err := manager.EnsureAuthenticated(); err != nil {
-		return "", fmt.Errorf("not logged in: %w", err)
-	}
-
-	// Fetch sites
-	resp, err := client.Execute(api.UserSitesQuery, "UserSites", nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to fetch sites: %w", err)
-	}
-
-	var result models.UserSitesResponse
-	if err := json.Unmarshal(resp.Data, &result); err != nil {
-		return "", fmt.Errorf("failed to parse sites: %w", err)
-	}
-
-	sites := result.UserSites
-	if len(sites) == 0 {
-		return "", fmt.Errorf("no sites found")
-	}
-
-	// Normalize the partial reference for matching
-	partial = strings.ToUpper(strings.TrimSpace(partial))
-
-	// Try to find a matching site
-	var matches []models.Site
-	for _, site := range sites {
-		ref := strings.ToUpper(site.Reference)
-		if ref == partial {
-			// Exact match
-			return site.Reference, nil
-		}
-		if strings.HasPrefix(ref, partial) {
-			matches = append(matches, site)
-		}
-	}
-
-	if len(matches) == 1 {
-		return matches[0].Reference, nil
-	}
-
-	if len(matches) > 1 {
-		return "", fmt.Errorf("ambiguous site reference '%s' matches %d sites", partial, len(matches))
-	}
-
-	// If only one site, use it regardless of the input
-	if len(sites) == 1 {
-		return sites[0].Reference, nil
-	}
-
-	return "", fmt.Errorf("no site found matching '%s'", partial)
-}
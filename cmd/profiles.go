@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/huh"
+	"github.com/spf13/cobra"
+
+	"github.com/pietern/frankie/internal/auth"
+	"github.com/pietern/frankie/internal/config"
+	"github.com/pietern/frankie/internal/output"
+)
+
+var profilesRemoveYes bool
+
+var profilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "Manage profiles for multiple accounts",
+	Long: `Manage profiles, each with its own login, history database and settings.
+
+The active profile is selected by --profile, then $FRANKIE_PROFILE, then the
+profile chosen with 'frankie profiles use', and is "default" otherwise.
+
+Examples:
+  frankie login --profile parents
+  frankie usage --profile parents
+  frankie profiles use parents`,
+	RunE: runProfilesList,
+}
+
+var profilesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles",
+	Args:  cobra.NoArgs,
+	RunE:  runProfilesList,
+}
+
+var profilesUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Set the profile used by default",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProfilesUse,
+}
+
+var profilesRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a profile with its login and history",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProfilesRemove,
+}
+
+func init() {
+	rootCmd.AddCommand(profilesCmd)
+	profilesCmd.AddCommand(profilesListCmd)
+	profilesCmd.AddCommand(profilesUseCmd)
+	profilesCmd.AddCommand(profilesRemoveCmd)
+	profilesRemoveCmd.Flags().BoolVarP(&profilesRemoveYes, "yes", "y", false, "remove without asking for confirmation")
+}
+
+// ProfileInfo describes a profile in list output
+type ProfileInfo struct {
+	Name     string `json:"name"`
+	Active   bool   `json:"active"`
+	Email    string `json:"email,omitempty"`
+	LoggedIn bool   `json:"logged_in"`
+}
+
+func runProfilesList(cmd *cobra.Command, args []string) error {
+	names, err := config.ListProfiles()
+	if err != nil {
+		return err
+	}
+
+	active := config.GetProfile()
+	var profiles []ProfileInfo
+	for _, name := range names {
+		info := ProfileInfo{Name: name, Active: name == active}
+		if creds, err := auth.LoadProfileCredentials(name); err == nil && creds != nil {
+			info.Email = extractEmailFromToken(creds.AuthToken)
+			info.LoggedIn = !auth.IsTokenExpired(creds.AuthToken, 0)
+		}
+		profiles = append(profiles, info)
+	}
+
+	// The active profile may not have been created yet
+	if !config.ProfileExists(active) {
+		profiles = append(profiles, ProfileInfo{Name: active, Active: true})
+	}
+
+	if isStructuredOutput() {
+		return renderOutput(profiles, profiles)
+	}
+
+	headers := []string{"", "Profile", "Email", "Status"}
+	var rows [][]string
+	for _, p := range profiles {
+		marker := ""
+		if p.Active {
+			marker = "*"
+		}
+		status := "not logged in"
+		if p.LoggedIn {
+			status = "logged in"
+		} else if p.Email != "" {
+			status = "session expired"
+		}
+		rows = append(rows, []string{marker, p.Name, p.Email, status})
+	}
+
+	output.Table(headers, rows)
+	return nil
+}
+
+func runProfilesUse(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if err := config.ValidateProfileName(name); err != nil {
+		return err
+	}
+	if err := config.SetCurrentProfile(name); err != nil {
+		return err
+	}
+
+	fmt.Printf("Using profile '%s'\n", name)
+	if _, source := config.GetProfileWithSource(); source == config.ProfileSourceEnv {
+		fmt.Printf("Note: $%s is set and takes precedence\n", config.ProfileEnv)
+	}
+	if creds, err := auth.LoadProfileCredentials(name); err == nil && creds == nil {
+		fmt.Println()
+		fmt.Println("Run 'frankie login' to authenticate.")
+	}
+	return nil
+}
+
+func runProfilesRemove(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if err := config.ValidateProfileName(name); err != nil {
+		return err
+	}
+	if name == config.DefaultProfile {
+		return fmt.Errorf("the default profile cannot be removed (use 'frankie logout' to clear its credentials)")
+	}
+	if !config.ProfileExists(name) {
+		return fmt.Errorf("profile '%s' does not exist", name)
+	}
+
+	if !profilesRemoveYes {
+		if !isTerminal(os.Stdin) {
+			return fmt.Errorf("refusing to remove profile '%s' without confirmation (use --yes)", name)
+		}
+		confirmed := false
+		prompt := huh.NewConfirm().
+			Title(fmt.Sprintf("Remove profile '%s' with its credentials and history?", name)).
+			Value(&confirmed)
+		if err := prompt.Run(); err != nil {
+			return fmt.Errorf("prompt cancelled: %w", err)
+		}
+		if !confirmed {
+			return nil
+		}
+	}
+
+	if err := auth.DeleteProfileCredentials(name); err != nil {
+		return err
+	}
+	if err := config.RemoveProfile(name); err != nil {
+		return err
+	}
+	fmt.Printf("Removed profile '%s'\n", name)
+	return nil
+}
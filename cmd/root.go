@@ -6,15 +6,60 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/pietern/frankie/internal/auth"
+	"github.com/pietern/frankie/internal/config"
 	frankieErrors "github.com/pietern/frankie/internal/errors"
+	"github.com/pietern/frankie/internal/output"
 )
 
-var outputFormat string
+var (
+	outputFormat  string
+	outputColumns []string
+	outputQuery   string
+	outputOptions *output.Options
+	profileName   string
+)
 
 var rootCmd = &cobra.Command{
 	Use:   "frankie",
 	Short: "CLI tool for Frank Energie",
 	Long:  `Frankie is a command-line interface for interacting with the Frank Energie API.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.CheckDirs(); err != nil {
+			return err
+		}
+		if oldDir, err := config.MigrateConfigDir(); err != nil {
+			fmt.Fprintln(os.Stderr, "Warning:", err)
+		} else if oldDir != "" {
+			fmt.Fprintf(os.Stderr, "Moved settings and credentials from %s to %s\n", oldDir, config.GetConfigDir())
+		}
+		config.SetProfile(profileName)
+		if err := config.ValidateProfileName(config.GetProfile()); err != nil {
+			return err
+		}
+		if isTerminal(os.Stdin) {
+			auth.PromptPassphrase = promptPassphrase
+		}
+		// Settings are not applied to the config commands, so they can fix a broken settings file
+		if cmd.Parent() != configCmd {
+			if err := applySettings(cmd); err != nil {
+				return err
+			}
+		}
+		if err := resolveDateFlags(cmd); err != nil {
+			return err
+		}
+
+		opts, err := output.ParseOptions(outputFormat, outputColumns)
+		if err != nil {
+			return err
+		}
+		if err := opts.SetQuery(outputQuery); err != nil {
+			return err
+		}
+		outputOptions = opts
+		return nil
+	},
 }
 
 func Execute() {
@@ -25,9 +70,54 @@ func Execute() {
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "output format: table or json")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "output format: table, json, yaml, csv, tsv, ndjson, influx, template=<template> or template-file=<path>")
+	rootCmd.PersistentFlags().StringSliceVar(&outputColumns, "columns", nil, "columns to show in table, csv and tsv output, by field name")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "profile to use (default: $FRANKIE_PROFILE or the current profile)")
+	rootCmd.PersistentFlags().StringVar(&outputQuery, "query", "", "JMESPath query to filter json, yaml or table output (e.g. 'electricityPrices[].allInPrice')")
+	bindSetting(rootCmd.PersistentFlags(), "output", config.SettingOutput)
 }
 
 func getOutputFormat() string {
-	return outputFormat
+	return string(getOutputOptions().Format)
+}
+
+// getOutputOptions returns the parsed --output and --columns flags
+func getOutputOptions() *output.Options {
+	if outputOptions == nil {
+		return &output.Options{Format: output.FormatTable}
+	}
+	return outputOptions
+}
+
+// isStructuredOutput reports whether output is rendered from data (a
+// machine-readable format, a template, selected columns or a query) rather than
+// by the command's own table layout
+func isStructuredOutput() bool {
+	return getOutputOptions().Structured()
+}
+
+// usesOutputRecords reports whether structured output is rendered from the
+// records of a command rather than its document
+func usesOutputRecords() bool {
+	return isStructuredOutput() && getOutputOptions().UsesRecords()
+}
+
+// isStreamingOutput reports whether records are written as soon as they are
+// fetched, which is the case for line-based formats
+func isStreamingOutput() bool {
+	switch getOutputOptions().Format {
+	case output.FormatNDJSON, output.FormatInflux:
+		return true
+	}
+	return false
+}
+
+// streamRecords writes a batch of records to stdout in a line-based format
+func streamRecords(records interface{}) error {
+	return output.Render(os.Stdout, getOutputOptions(), nil, records)
+}
+
+// renderOutput writes the document (JSON) or its records (other formats) to stdout
+func renderOutput(doc, records interface{}) error {
+	return output.Render(os.Stdout, getOutputOptions(), doc, records)
 }
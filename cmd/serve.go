@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pietern/frankie/internal/api"
+	"github.com/pietern/frankie/internal/auth"
+	"github.com/pietern/frankie/internal/config"
+	"github.com/pietern/frankie/internal/models"
+)
+
+const (
+	sourcePrices    = "prices"
+	sourceSites     = "sites"
+	sourceBatteries = "batteries"
+	sourceChargers  = "chargers"
+	sourceVehicles  = "vehicles"
+)
+
+var (
+	serveInterval   time.Duration
+	serveSite       string
+	serveResolution int
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a long-lived server that exports live data",
+	Long: `Run a long-lived server that exports prices, costs, usage and device
+state to other systems.
+
+The metrics and mqtt servers refresh data in the background every
+--interval, so clients never trigger API requests themselves. The http
+server fetches data on request and caches it. The login token is renewed
+as needed.`,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.PersistentFlags().StringVarP(&serveSite, "site", "s", "", "site reference (default: all sites)")
+	serveCmd.PersistentFlags().IntVarP(&serveResolution, "resolution", "r", resolution60Min, "price resolution in minutes (15 or 60)")
+	bindSetting(serveCmd.PersistentFlags(), "resolution", config.SettingResolution)
+	bindSiteFlag(serveCmd, "site")
+}
+
+// liveState is the latest data fetched by a poller. Sources that fail to
+// refresh keep their previous data.
+type liveState struct {
+	// Prices holds today's and tomorrow's prices by segment
+	Prices    map[string][]models.Price
+	Sites     []siteState
+	Batteries []batteryState
+	Chargers  []models.EnodeCharger
+	Vehicles  []models.EnodeVehicle
+
+	// Refreshed holds the time of the last successful refresh by source
+	Refreshed map[string]time.Time
+	// Failed holds the sources whose last refresh failed
+	Failed map[string]bool
+}
+
+// siteState is the live data of a single site
+type siteState struct {
+	Site      models.Site                 `json:"site"`
+	Summary   *models.MonthSummary        `json:"summary"`
+	UsageDate string                      `json:"usageDate"`
+	Usage     *models.PeriodUsageAndCosts `json:"usage"`
+}
+
+// batteryState is the live data of a single smart battery
+type batteryState struct {
+	Battery models.SmartBattery         `json:"battery"`
+	Summary *models.SmartBatterySummary `json:"summary"`
+}
+
+// poller periodically refreshes live data from the API
+type poller struct {
+	client     *api.Client
+	manager    *auth.Manager
+	resolution string
+	siteRef    string
+	interval   time.Duration
+
+	// onRefresh is called with a snapshot after every refresh
+	onRefresh func(state liveState)
+
+	mu    sync.RWMutex
+	state liveState
+}
+
+// addIntervalFlag adds the --interval flag to a server that polls the API
+func addIntervalFlag(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&serveInterval, "interval", 5*time.Minute, "how often to refresh data from the API")
+}
+
+// newPoller creates a poller from the serve flags
+func newPoller() (*poller, error) {
+	if serveInterval < time.Minute {
+		return nil, fmt.Errorf("invalid interval: %s (must be at least 1m)", serveInterval)
+	}
+
+	resolution, err := priceResolution(serveResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	client := api.NewClient()
+	p := &poller{
+		client:     client,
+		manager:    auth.NewManager(client),
+		resolution: resolution,
+		interval:   serveInterval,
+		state: liveState{
+			Prices:    make(map[string][]models.Price),
+			Refreshed: make(map[string]time.Time),
+			Failed:    make(map[string]bool),
+		},
+	}
+
+	if serveSite != "" {
+		p.siteRef, err = resolveSiteReference(client, serveSite)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// run refreshes immediately and then every interval until the context is done
+func (p *poller) run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.refresh()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// snapshot returns a copy of the current state. Refreshes replace slices
+// rather than modifying them, so only the maps need to be copied.
+func (p *poller) snapshot() liveState {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	state := p.state
+	state.Prices = maps.Clone(p.state.Prices)
+	state.Refreshed = maps.Clone(p.state.Refreshed)
+	state.Failed = maps.Clone(p.state.Failed)
+	return state
+}
+
+// refresh fetches all sources, keeping the previous data of failed sources
+func (p *poller) refresh() {
+	start := time.Now()
+
+	// Renew the token before it expires; account data needs a valid login
+	authErr := p.manager.EnsureAuthenticated()
+	if authErr != nil {
+		authErr = fmt.Errorf("not logged in: %w", authErr)
+	}
+
+	p.update(sourcePrices, p.refreshPrices, nil)
+	p.update(sourceSites, p.refreshSites, authErr)
+	p.update(sourceBatteries, p.refreshBatteries, authErr)
+	p.update(sourceChargers, p.refreshChargers, authErr)
+	p.update(sourceVehicles, p.refreshVehicles, authErr)
+
+	slog.Debug("refreshed live data", "duration", time.Since(start).Round(time.Millisecond))
+
+	if p.onRefresh != nil {
+		p.onRefresh(p.snapshot())
+	}
+}
+
+// update runs a source's refresh function and records the outcome
+func (p *poller) update(source string, refresh func() (func(*liveState), error), precondition error) {
+	var apply func(*liveState)
+	err := precondition
+	if err == nil {
+		apply, err = refresh()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err != nil {
+		slog.Warn("failed to refresh", "source", source, "error", err)
+		p.state.Failed[source] = true
+		return
+	}
+
+	apply(&p.state)
+	p.state.Failed[source] = false
+	p.state.Refreshed[source] = time.Now()
+}
+
+func (p *poller) refreshPrices() (func(*liveState), error) {
+	now := time.Now().In(marketLocation())
+	dates := []string{now.Format("2006-01-02")}
+	if latest := latestPriceDate(); latest != dates[0] {
+		dates = append(dates, latest)
+	}
+
+	prices := make(map[string][]models.Price)
+	for _, date := range dates {
+		var result *models.MarketPrices
+		var err error
+		if p.siteRef != "" {
+			result, err = fetchCustomerPrices(p.client, date, p.siteRef)
+		} else {
+			result, err = fetchPublicPrices(p.client, date, p.resolution)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			continue
+		}
+		prices["electricity"] = append(prices["electricity"], result.ElectricityPrices...)
+		prices["gas"] = append(prices["gas"], result.GasPrices...)
+	}
+
+	return func(s *liveState) { s.Prices = prices }, nil
+}
+
+func (p *poller) refreshSites() (func(*liveState), error) {
+	sites, err := fetchSites(p.client)
+	if err != nil {
+		return nil, err
+	}
+
+	var states []siteState
+	for _, site := range sites {
+		if p.siteRef != "" && site.Reference != p.siteRef {
+			continue
+		}
+
+		state := siteState{Site: site}
+		state.Summary, err = fetchMonthSummary(p.client, site.Reference)
+		if err != nil {
+			return nil, err
+		}
+
+		// Usage is only complete up to the last meter reading
+		state.UsageDate = formatDate(site.LastMeterReadingDate)
+		if state.Summary != nil && state.Summary.LastMeterReadingDate != "" {
+			state.UsageDate = formatDate(state.Summary.LastMeterReadingDate)
+		}
+		if state.UsageDate != "" {
+			state.Usage, err = fetchUsage(p.client, site.Reference, state.UsageDate)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		states = append(states, state)
+	}
+
+	return func(s *liveState) { s.Sites = states }, nil
+}
+
+func (p *poller) refreshBatteries() (func(*liveState), error) {
+	batteries, err := fetchBatteries(p.client)
+	if errors.Is(err, api.ErrSmartTradingNotEnabled) {
+		batteries, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var states []batteryState
+	for _, b := range batteries {
+		details, err := fetchBatteryDetails(p.client, b.ID)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, batteryState{Battery: b, Summary: details.SmartBatterySummary})
+	}
+
+	return func(s *liveState) { s.Batteries = states }, nil
+}
+
+func (p *poller) refreshChargers() (func(*liveState), error) {
+	chargers, err := fetchChargers(p.client)
+	if errors.Is(err, api.ErrSmartChargingNotEnabled) {
+		chargers, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return func(s *liveState) { s.Chargers = chargers }, nil
+}
+
+func (p *poller) refreshVehicles() (func(*liveState), error) {
+	vehicles, err := fetchVehicles(p.client)
+	if errors.Is(err, api.ErrSmartChargingNotEnabled) {
+		vehicles, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return func(s *liveState) { s.Vehicles = vehicles }, nil
+}
+
+// priceAt returns the price interval that contains t, or nil
+func priceAt(prices []models.Price, t time.Time) *models.Price {
+	for i := range prices {
+		if !t.Before(prices[i].From) && t.Before(prices[i].Till) {
+			return &prices[i]
+		}
+	}
+	return nil
+}
+
+// signalContext returns a context that is cancelled on SIGINT or SIGTERM
+func signalContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+}
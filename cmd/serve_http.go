@@ -0,0 +1,353 @@
+package cmd
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pietern/frankie/internal/api"
+	"github.com/pietern/frankie/internal/auth"
+	"github.com/pietern/frankie/internal/cache"
+	"github.com/pietern/frankie/internal/config"
+)
+
+// maxUsageDays limits the range of a single usage request
+const maxUsageDays = 92
+
+var (
+	serveHTTPListen   string
+	serveHTTPToken    string
+	serveHTTPCacheTTL time.Duration
+)
+
+var serveHTTPCmd = &cobra.Command{
+	Use:   "http",
+	Short: "Serve a read-only REST API",
+	Long: `Serve a read-only JSON API for prices, usage, invoices and devices.
+
+Endpoints:
+  GET /prices?date=&site=&resolution=    market or customer prices of a date
+  GET /usage?site=&date=                 usage and costs of a date
+  GET /usage?site=&from=&to=             usage and costs of a date range
+  GET /invoices?site=                    invoices of a site
+  GET /summary?site=                     month summary of a site
+  GET /sites                             delivery sites
+  GET /batteries                         smart batteries
+  GET /batteries/{id}/sessions?from=&to= trading sessions of a battery
+  GET /chargers                          smart chargers
+  GET /vehicles                          vehicles
+
+Responses from the API are cached for --cache-ttl, and the login token is
+renewed as needed. Requests must carry "Authorization: Bearer <token>" when
+--token or FRANKIE_HTTP_TOKEN is set.
+
+Example:
+  frankie serve http --listen 127.0.0.1:8080
+  curl 'http://127.0.0.1:8080/usage?from=2025-01-01&to=2025-01-07'`,
+	RunE: runServeHTTP,
+}
+
+func init() {
+	serveCmd.AddCommand(serveHTTPCmd)
+	serveHTTPCmd.Flags().StringVar(&serveHTTPListen, "listen", "127.0.0.1:8080", "address to listen on")
+	serveHTTPCmd.Flags().StringVar(&serveHTTPToken, "token", "", "bearer token required from clients (default: $FRANKIE_HTTP_TOKEN)")
+	serveHTTPCmd.Flags().DurationVar(&serveHTTPCacheTTL, "cache-ttl", 5*time.Minute, "how long to cache API responses (0 to disable)")
+	bindSetting(serveHTTPCmd.Flags(), "cache-ttl", config.SettingCacheTTL)
+}
+
+// httpError is an error with the status code to respond with
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e *httpError) Error() string {
+	return e.err.Error()
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return &httpError{status: http.StatusBadRequest, err: fmt.Errorf(format, args...)}
+}
+
+// restAPI answers REST requests from the shared client
+type restAPI struct {
+	client     *api.Client
+	resolution string
+	site       string
+}
+
+func runServeHTTP(cmd *cobra.Command, args []string) error {
+	resolution, err := priceResolution(serveResolution)
+	if err != nil {
+		return err
+	}
+
+	token := serveHTTPToken
+	if token == "" {
+		token = os.Getenv("FRANKIE_HTTP_TOKEN")
+	}
+
+	client := api.NewClient()
+	client.SetTokenSource(auth.NewManager(client).TokenSource())
+	if serveHTTPCacheTTL > 0 {
+		client.SetCache(cache.NewMemory(), serveHTTPCacheTTL)
+	}
+
+	a := &restAPI{client: client, resolution: resolution, site: serveSite}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /prices", a.handle(a.prices))
+	mux.HandleFunc("GET /usage", a.handle(a.usage))
+	mux.HandleFunc("GET /invoices", a.handle(a.invoices))
+	mux.HandleFunc("GET /summary", a.handle(a.summary))
+	mux.HandleFunc("GET /sites", a.handle(a.sites))
+	mux.HandleFunc("GET /batteries", a.handle(a.batteries))
+	mux.HandleFunc("GET /batteries/{id}/sessions", a.handle(a.batterySessions))
+	mux.HandleFunc("GET /chargers", a.handle(a.chargers))
+	mux.HandleFunc("GET /vehicles", a.handle(a.vehicles))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSONError(w, http.StatusNotFound, "not found")
+	})
+
+	var handler http.Handler = mux
+	if token != "" {
+		handler = requireBearerToken(token, mux)
+	} else if !isLoopbackAddress(serveHTTPListen) {
+		slog.Warn("serving account data without a token; set --token to require one", "address", serveHTTPListen)
+	}
+
+	ctx, stop := signalContext(cmd)
+	defer stop()
+	return serveHTTP(ctx, serveHTTPListen, handler)
+}
+
+// handle wraps an endpoint that returns a value to encode as JSON
+func (a *restAPI) handle(endpoint func(r *http.Request) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		value, err := endpoint(r)
+		if err != nil {
+			status := errorStatus(err)
+			slog.Debug("request failed", "path", r.URL.Path, "status", status, "error", err)
+			writeJSONError(w, status, err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(value); err != nil {
+			slog.Debug("failed to write response", "error", err)
+		}
+		slog.Debug("request", "path", r.URL.Path, "duration", time.Since(start).Round(time.Millisecond))
+	}
+}
+
+// errorStatus returns the HTTP status code for an endpoint error
+func errorStatus(err error) int {
+	var he *httpError
+	switch {
+	case errors.As(err, &he):
+		return he.status
+	case errors.Is(err, api.ErrAuthRequired):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, api.ErrSmartTradingNotEnabled), errors.Is(err, api.ErrSmartChargingNotEnabled), errors.Is(err, api.ErrNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// requireBearerToken rejects requests without the given bearer token
+func requireBearerToken(token string, next http.Handler) http.Handler {
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(got, expected) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="frankie"`)
+			writeJSONError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopbackAddress reports whether a listen address only accepts local connections
+func isLoopbackAddress(addr string) bool {
+	host, _, found := strings.Cut(addr, ":")
+	if !found {
+		return false
+	}
+	return host == "localhost" || host == "127.0.0.1" || strings.HasPrefix(addr, "[::1]:")
+}
+
+// siteReference resolves the site parameter, falling back to --site
+func (a *restAPI) siteReference(r *http.Request) (string, error) {
+	partial := r.URL.Query().Get("site")
+	if partial == "" {
+		partial = a.site
+	}
+
+	sites, err := fetchSites(a.client)
+	if err != nil {
+		return "", err
+	}
+	ref, err := matchSiteReference(sites, partial)
+	if err != nil {
+		return "", &httpError{status: http.StatusBadRequest, err: err}
+	}
+	return ref, nil
+}
+
+// dateParam returns a date parameter, or def if it is not set
+func dateParam(r *http.Request, name, def string) (string, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, nil
+	}
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		return "", badRequest("invalid %s '%s' (expected YYYY-MM-DD)", name, value)
+	}
+	return value, nil
+}
+
+func (a *restAPI) prices(r *http.Request) (interface{}, error) {
+	date, err := dateParam(r, "date", time.Now().In(marketLocation()).Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	resolution := a.resolution
+	if value := r.URL.Query().Get("resolution"); value != "" {
+		minutes, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, badRequest("invalid resolution '%s'", value)
+		}
+		if resolution, err = priceResolution(minutes); err != nil {
+			return nil, &httpError{status: http.StatusBadRequest, err: err}
+		}
+	}
+
+	if r.URL.Query().Get("site") != "" || a.site != "" {
+		siteRef, err := a.siteReference(r)
+		if err != nil {
+			return nil, err
+		}
+		return fetchCustomerPrices(a.client, date, siteRef)
+	}
+	return fetchPublicPrices(a.client, date, resolution)
+}
+
+func (a *restAPI) usage(r *http.Request) (interface{}, error) {
+	query := r.URL.Query()
+	if query.Get("date") != "" && query.Get("from") != "" {
+		return nil, badRequest("date and from cannot be combined")
+	}
+
+	var dates []string
+	if query.Get("from") != "" {
+		from, err := dateParam(r, "from", "")
+		if err != nil {
+			return nil, err
+		}
+		to, err := dateParam(r, "to", time.Now().AddDate(0, 0, -1).Format("2006-01-02"))
+		if err != nil {
+			return nil, err
+		}
+		if dates, err = datesBetween(from, to); err != nil {
+			return nil, &httpError{status: http.StatusBadRequest, err: err}
+		}
+		if len(dates) > maxUsageDays {
+			return nil, badRequest("date range is longer than %d days", maxUsageDays)
+		}
+	} else {
+		date, err := dateParam(r, "date", time.Now().Format("2006-01-02"))
+		if err != nil {
+			return nil, err
+		}
+		dates = []string{date}
+	}
+
+	siteRef, err := a.siteReference(r)
+	if err != nil {
+		return nil, err
+	}
+
+	days := []UsageDay{}
+	for _, date := range dates {
+		usage, err := fetchUsage(a.client, siteRef, date)
+		if err != nil {
+			return nil, err
+		}
+		if usage != nil {
+			days = append(days, UsageDay{Date: date, PeriodUsageAndCosts: usage})
+		}
+	}
+	return days, nil
+}
+
+func (a *restAPI) invoices(r *http.Request) (interface{}, error) {
+	siteRef, err := a.siteReference(r)
+	if err != nil {
+		return nil, err
+	}
+	return fetchInvoices(a.client, siteRef)
+}
+
+func (a *restAPI) summary(r *http.Request) (interface{}, error) {
+	siteRef, err := a.siteReference(r)
+	if err != nil {
+		return nil, err
+	}
+	return fetchMonthSummary(a.client, siteRef)
+}
+
+func (a *restAPI) sites(r *http.Request) (interface{}, error) {
+	return fetchSites(a.client)
+}
+
+func (a *restAPI) batteries(r *http.Request) (interface{}, error) {
+	return fetchBatteries(a.client)
+}
+
+func (a *restAPI) batterySessions(r *http.Request) (interface{}, error) {
+	to, err := dateParam(r, "to", time.Now().Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	t, _ := time.Parse("2006-01-02", to)
+	from, err := dateParam(r, "from", t.AddDate(0, 0, -30).Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	dates, err := datesBetween(from, to)
+	if err != nil {
+		return nil, &httpError{status: http.StatusBadRequest, err: err}
+	}
+	if len(dates) > batterySessionChunkDays {
+		return nil, badRequest("date range is longer than %d days", batterySessionChunkDays)
+	}
+
+	return fetchBatterySessions(a.client, r.PathValue("id"), from, to)
+}
+
+func (a *restAPI) chargers(r *http.Request) (interface{}, error) {
+	return fetchChargers(a.client)
+}
+
+func (a *restAPI) vehicles(r *http.Request) (interface{}, error) {
+	return fetchVehicles(a.client)
+}
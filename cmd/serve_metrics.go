@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pietern/frankie/internal/metrics"
+	"github.com/pietern/frankie/internal/models"
+)
+
+var serveMetricsListen string
+
+var serveMetricsCmd = &cobra.Command{
+	Use:   "metrics",
+	Short: "Serve live data as Prometheus metrics",
+	Long: `Serve prices, month-summary costs, the latest daily usage, battery state
+and charger/vehicle charge state as Prometheus gauges on /metrics.
+
+Scrapes are answered from data cached by the background refresh. The
+current and next price intervals are determined at scrape time.
+
+Example scrape config:
+  - job_name: frankie
+    static_configs:
+      - targets: ['localhost:9469']`,
+	RunE: runServeMetrics,
+}
+
+func init() {
+	serveCmd.AddCommand(serveMetricsCmd)
+	addIntervalFlag(serveMetricsCmd)
+	serveMetricsCmd.Flags().StringVar(&serveMetricsListen, "listen", ":9469", "address to listen on")
+}
+
+func runServeMetrics(cmd *cobra.Command, args []string) error {
+	p, err := newPoller()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signalContext(cmd)
+	defer stop()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		set := collectMetrics(p.snapshot(), time.Now())
+		w.Header().Set("Content-Type", metrics.ContentType)
+		if _, err := set.WriteTo(w); err != nil {
+			slog.Debug("failed to write metrics", "error", err)
+		}
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, `<html><body><a href="/metrics">Metrics</a></body></html>`)
+	})
+
+	go p.run(ctx)
+	return serveHTTP(ctx, serveMetricsListen, mux)
+}
+
+// serveHTTP serves a handler until the context is done, then shuts down gracefully
+func serveHTTP(ctx context.Context, addr string, handler http.Handler) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		slog.Info("listening", "address", addr)
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("failed to serve: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// collectMetrics converts the live state into gauges
+func collectMetrics(state liveState, now time.Time) *metrics.Set {
+	set := metrics.NewSet()
+
+	for _, segment := range []string{"electricity", "gas"} {
+		current := priceAt(state.Prices[segment], now)
+		if current == nil {
+			continue
+		}
+		addPriceMetrics(set, segment, "current", current)
+		if next := priceAt(state.Prices[segment], current.Till); next != nil {
+			addPriceMetrics(set, segment, "next", next)
+		}
+	}
+
+	for _, s := range state.Sites {
+		site := metrics.L("site", s.Site.Reference)
+		if m := s.Summary; m != nil {
+			set.Gauge("frankie_month_actual_costs_euros", "Actual costs this month until the last meter reading.", m.ActualCostsUntilLastMeterReadingDate, site)
+			set.Gauge("frankie_month_expected_costs_to_date_euros", "Expected costs this month until the last meter reading.", m.ExpectedCostsUntilLastMeterReadingDate, site)
+			set.Gauge("frankie_month_expected_costs_euros", "Expected costs for the whole month.", m.ExpectedCosts, site)
+			set.Gauge("frankie_month_meter_reading_completeness_ratio", "Share of days this month with complete meter readings.", m.MeterReadingDayCompleteness, site)
+		}
+
+		if s.Usage == nil {
+			continue
+		}
+		if date, err := time.ParseInLocation("2006-01-02", s.UsageDate, marketLocation()); err == nil {
+			set.Gauge("frankie_daily_usage_date_timestamp_seconds", "Start of the day the daily usage is reported for.", float64(date.Unix()), site)
+		}
+		categories := []struct {
+			segment  string
+			category *models.EnergyCategory
+		}{
+			{"electricity", s.Usage.Electricity},
+			{"gas", s.Usage.Gas},
+			{"feedin", s.Usage.FeedIn},
+		}
+		for _, c := range categories {
+			if c.category == nil {
+				continue
+			}
+			labels := []metrics.Label{site, metrics.L("segment", c.segment), metrics.L("unit", c.category.Unit)}
+			set.Gauge("frankie_daily_usage", "Usage on the last day with complete meter readings.", c.category.UsageTotal, labels...)
+			set.Gauge("frankie_daily_costs_euros", "Costs on the last day with complete meter readings.", c.category.CostsTotal, labels[:2]...)
+		}
+	}
+
+	for _, b := range state.Batteries {
+		labels := []metrics.Label{metrics.L("device", b.Battery.ID), metrics.L("brand", b.Battery.Brand)}
+		set.Gauge("frankie_battery_capacity_kwh", "Capacity of the smart battery.", b.Battery.Capacity, labels...)
+		if s := b.Summary; s != nil {
+			set.Gauge("frankie_battery_state_of_charge_percent", "Last known state of charge of the smart battery.", s.LastKnownStateOfCharge, labels...)
+			set.Gauge("frankie_battery_total_result_euros", "Total trading result of the smart battery.", s.TotalResult, labels...)
+			if t, err := time.Parse(time.RFC3339, s.LastUpdate); err == nil {
+				set.Gauge("frankie_battery_last_update_timestamp_seconds", "Time of the last smart battery update.", float64(t.Unix()), labels...)
+			}
+		}
+	}
+
+	for _, c := range state.Chargers {
+		addDeviceMetrics(set, "charger", c.ID, c.Information, c.ChargeState, c.IsReachable)
+	}
+	for _, v := range state.Vehicles {
+		addDeviceMetrics(set, "vehicle", v.ID, v.Information, v.ChargeState, v.IsReachable)
+	}
+
+	for _, source := range []string{sourcePrices, sourceSites, sourceBatteries, sourceChargers, sourceVehicles} {
+		label := metrics.L("source", source)
+		if failed, ok := state.Failed[source]; ok {
+			set.Bool("frankie_refresh_success", "Whether the last refresh of a source succeeded.", !failed, label)
+		}
+		if t, ok := state.Refreshed[source]; ok {
+			set.Gauge("frankie_refresh_timestamp_seconds", "Time of the last successful refresh of a source.", float64(t.Unix()), label)
+		}
+	}
+
+	return set
+}
+
+// addPriceMetrics adds gauges for all components of a price interval
+func addPriceMetrics(set *metrics.Set, segment, interval string, p *models.Price) {
+	labels := []metrics.Label{metrics.L("segment", segment), metrics.L("interval", interval)}
+	set.Gauge("frankie_price_market_euros", "Market price per unit.", p.MarketPrice, labels...)
+	set.Gauge("frankie_price_market_tax_euros", "VAT on the market price per unit.", p.MarketPriceTax, labels...)
+	set.Gauge("frankie_price_sourcing_markup_euros", "Sourcing markup per unit.", p.SourcingMarkupPrice, labels...)
+	set.Gauge("frankie_price_energy_tax_euros", "Energy tax per unit.", p.EnergyTaxPrice, labels...)
+	set.Gauge("frankie_price_market_plus_euros", "Market price including VAT per unit.", p.MarketPricePlus, labels...)
+	set.Gauge("frankie_price_all_in_euros", "All-in price per unit.", p.AllInPrice, labels...)
+	set.Gauge("frankie_price_start_timestamp_seconds", "Start of the price interval.", float64(p.From.Unix()), labels...)
+}
+
+// addDeviceMetrics adds gauges for the charge state of a charger or vehicle
+func addDeviceMetrics(set *metrics.Set, kind, id string, info *models.DeviceInfo, state *models.ChargeState, reachable bool) {
+	// Every series of a family has the same labels, so they are empty without information
+	var brand, model string
+	if info != nil {
+		brand, model = info.Brand, info.Model
+	}
+	labels := []metrics.Label{metrics.L(kind, id), metrics.L("brand", brand), metrics.L("model", model)}
+
+	prefix := "frankie_" + kind + "_"
+	set.Bool(prefix+"reachable", fmt.Sprintf("Whether the %s is reachable.", kind), reachable, labels...)
+	if state == nil {
+		return
+	}
+	set.Gauge(prefix+"battery_level_percent", "Battery level.", state.BatteryLevel, labels...)
+	set.Gauge(prefix+"battery_capacity_kwh", "Battery capacity.", state.BatteryCapacity, labels...)
+	set.Gauge(prefix+"charge_limit_percent", "Charge limit.", state.ChargeLimit, labels...)
+	set.Gauge(prefix+"charge_rate_kw", "Charge rate.", state.ChargeRate, labels...)
+	set.Gauge(prefix+"range_km", "Estimated range.", state.Range, labels...)
+	set.Bool(prefix+"plugged_in", "Whether the charging cable is plugged in.", state.IsPluggedIn, labels...)
+	set.Bool(prefix+"charging", "Whether it is charging.", state.IsCharging, labels...)
+	set.Bool(prefix+"fully_charged", "Whether it is fully charged.", state.IsFullyCharged, labels...)
+}
@@ -0,0 +1,553 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/spf13/cobra"
+
+	"github.com/pietern/frankie/internal/models"
+)
+
+const (
+	// mqttTimeout limits how long to wait for the broker to acknowledge
+	mqttTimeout = 10 * time.Second
+
+	// mqttPriceCheckInterval is how often to check for a new current price interval
+	mqttPriceCheckInterval = 30 * time.Second
+)
+
+var (
+	serveMQTTBroker          string
+	serveMQTTUsername        string
+	serveMQTTPassword        string
+	serveMQTTClientID        string
+	serveMQTTTopicPrefix     string
+	serveMQTTDiscoveryPrefix string
+	serveMQTTNoDiscovery     bool
+)
+
+var serveMQTTCmd = &cobra.Command{
+	Use:   "mqtt",
+	Short: "Publish live data to an MQTT broker",
+	Long: `Publish live data to an MQTT broker as retained JSON messages, with
+Home Assistant MQTT discovery so sensors appear automatically.
+
+Topics (under --topic-prefix):
+  status                               online or offline
+  prices/<segment>/current             current price interval
+  prices/<segment>/next                next price interval
+  prices/<segment>/today               today's prices with min, max and average
+  prices/<segment>/tomorrow            tomorrow's prices, once published
+  sites/<site>/summary                 month summary
+  sites/<site>/usage                   usage of the last day with meter readings
+  batteries/<device>/state             smart battery and its trading result
+  chargers/<device>/state              charger charge state
+  vehicles/<device>/state              vehicle charge state
+
+The password can also be set with FRANKIE_MQTT_PASSWORD.
+
+Examples:
+  frankie serve mqtt --broker tcp://localhost:1883
+  frankie serve mqtt --broker ssl://broker:8883 --username frankie`,
+	RunE: runServeMQTT,
+}
+
+func init() {
+	serveCmd.AddCommand(serveMQTTCmd)
+	addIntervalFlag(serveMQTTCmd)
+	serveMQTTCmd.Flags().StringVar(&serveMQTTBroker, "broker", "tcp://localhost:1883", "broker URL (tcp://, ssl:// or ws://)")
+	serveMQTTCmd.Flags().StringVar(&serveMQTTUsername, "username", "", "broker username")
+	serveMQTTCmd.Flags().StringVar(&serveMQTTPassword, "password", "", "broker password")
+	serveMQTTCmd.Flags().StringVar(&serveMQTTClientID, "client-id", "frankie", "MQTT client ID")
+	serveMQTTCmd.Flags().StringVar(&serveMQTTTopicPrefix, "topic-prefix", "frankie", "prefix of all state topics")
+	serveMQTTCmd.Flags().StringVar(&serveMQTTDiscoveryPrefix, "discovery-prefix", "homeassistant", "Home Assistant discovery prefix")
+	serveMQTTCmd.Flags().BoolVar(&serveMQTTNoDiscovery, "no-discovery", false, "don't publish Home Assistant discovery configs")
+}
+
+func runServeMQTT(cmd *cobra.Command, args []string) error {
+	p, err := newPoller()
+	if err != nil {
+		return err
+	}
+
+	password := serveMQTTPassword
+	if password == "" {
+		password = os.Getenv("FRANKIE_MQTT_PASSWORD")
+	}
+
+	pub := &mqttPublisher{
+		prefix:          strings.TrimSuffix(serveMQTTTopicPrefix, "/"),
+		discoveryPrefix: strings.TrimSuffix(serveMQTTDiscoveryPrefix, "/"),
+		discovery:       !serveMQTTNoDiscovery,
+		poller:          p,
+	}
+
+	opts := pub.clientOptions(serveMQTTBroker, serveMQTTClientID).
+		SetUsername(serveMQTTUsername).
+		SetPassword(password)
+	pub.client = mqtt.NewClient(opts)
+
+	ctx, stop := signalContext(cmd)
+	defer stop()
+
+	slog.Info("connecting to broker", "broker", serveMQTTBroker)
+	token := pub.client.Connect()
+	select {
+	case <-token.Done():
+		if err := token.Error(); err != nil {
+			return fmt.Errorf("failed to connect to broker: %w", err)
+		}
+	case <-ctx.Done():
+		return nil
+	}
+
+	p.onRefresh = pub.publishState
+	go p.run(ctx)
+
+	// Prices are refreshed periodically, but the current interval changes in between
+	ticker := time.NewTicker(mqttPriceCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			pub.publish(pub.topic("status"), "offline")
+			pub.client.Disconnect(uint(time.Second / time.Millisecond))
+			return nil
+		case <-ticker.C:
+			pub.publishPrices(p.snapshot(), false)
+		}
+	}
+}
+
+// mqttPublisher publishes live state and discovery configs
+type mqttPublisher struct {
+	client          mqtt.Client
+	prefix          string
+	discoveryPrefix string
+	discovery       bool
+	poller          *poller
+
+	mu sync.Mutex
+	// announced holds the discovery config topics published on this connection
+	announced map[string]bool
+	// currentFrom holds the start of the last published current interval by segment
+	currentFrom map[string]time.Time
+}
+
+// clientOptions returns the options of a client that reconnects automatically
+// and marks the publisher offline when it disconnects unexpectedly
+func (m *mqttPublisher) clientOptions(broker, clientID string) *mqtt.ClientOptions {
+	return mqtt.NewClientOptions().
+		AddBroker(broker).
+		SetClientID(clientID).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetWill(m.topic("status"), "offline", 1, true).
+		SetOnConnectHandler(m.onConnect).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			slog.Warn("lost connection to broker", "error", err)
+		})
+}
+
+// onConnect marks the publisher online and republishes everything, as the
+// broker may have lost retained messages while disconnected
+func (m *mqttPublisher) onConnect(client mqtt.Client) {
+	slog.Info("connected to broker")
+
+	m.mu.Lock()
+	m.announced = make(map[string]bool)
+	m.currentFrom = make(map[string]time.Time)
+	m.mu.Unlock()
+
+	m.publish(m.topic("status"), "online")
+	go m.publishState(m.poller.snapshot())
+}
+
+func (m *mqttPublisher) topic(parts ...string) string {
+	return m.prefix + "/" + strings.Join(parts, "/")
+}
+
+// publish publishes a retained message to a topic. Strings are sent as-is and other
+// payloads as JSON.
+func (m *mqttPublisher) publish(topic string, payload interface{}) {
+	data, ok := payload.(string)
+	if !ok {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			slog.Warn("failed to encode payload", "topic", topic, "error", err)
+			return
+		}
+		data = string(encoded)
+	}
+
+	token := m.client.Publish(topic, 1, true, data)
+	if !token.WaitTimeout(mqttTimeout) {
+		slog.Warn("timed out publishing", "topic", topic)
+		return
+	}
+	if err := token.Error(); err != nil {
+		slog.Warn("failed to publish", "topic", topic, "error", err)
+	}
+}
+
+// publishState publishes all state topics
+func (m *mqttPublisher) publishState(state liveState) {
+	if !m.client.IsConnectionOpen() {
+		return
+	}
+
+	m.publishPrices(state, true)
+
+	for _, s := range state.Sites {
+		id := topicID(s.Site.Reference)
+		m.announceSite(id, s.Site)
+		if s.Summary != nil {
+			m.publish(m.topic("sites", id, "summary"), s.Summary)
+		}
+		if s.Usage != nil {
+			m.publish(m.topic("sites", id, "usage"), UsageDay{Date: s.UsageDate, PeriodUsageAndCosts: s.Usage})
+		}
+	}
+
+	for _, b := range state.Batteries {
+		id := topicID(b.Battery.ID)
+		m.announceBattery(id, b.Battery)
+		m.publish(m.topic("batteries", id, "state"), b)
+	}
+
+	for _, c := range state.Chargers {
+		id := topicID(c.ID)
+		m.announceChargeState("chargers", id, "Charger", c.Information)
+		m.publish(m.topic("chargers", id, "state"), c)
+	}
+	for _, v := range state.Vehicles {
+		id := topicID(v.ID)
+		m.announceChargeState("vehicles", id, "Vehicle", v.Information)
+		m.publish(m.topic("vehicles", id, "state"), v)
+	}
+}
+
+// priceDay is the payload of the today and tomorrow price topics
+type priceDay struct {
+	Date    string         `json:"date"`
+	Min     *float64       `json:"min"`
+	Max     *float64       `json:"max"`
+	Average *float64       `json:"average"`
+	Prices  []models.Price `json:"prices"`
+}
+
+func newPriceDay(date string, prices []models.Price) priceDay {
+	day := priceDay{Date: date, Prices: []models.Price{}}
+	sum := 0.0
+	for _, p := range prices {
+		if p.From.In(marketLocation()).Format("2006-01-02") != date {
+			continue
+		}
+		day.Prices = append(day.Prices, p)
+		if day.Min == nil || p.AllInPrice < *day.Min {
+			day.Min = &p.AllInPrice
+		}
+		if day.Max == nil || p.AllInPrice > *day.Max {
+			day.Max = &p.AllInPrice
+		}
+		sum += p.AllInPrice
+	}
+	if len(day.Prices) > 0 {
+		average := sum / float64(len(day.Prices))
+		day.Average = &average
+	}
+	return day
+}
+
+// publishPrices publishes the current and next price intervals when they
+// change, and all prices of today and tomorrow if forced
+func (m *mqttPublisher) publishPrices(state liveState, force bool) {
+	now := time.Now()
+	today := now.In(marketLocation()).Format("2006-01-02")
+	tomorrow := now.In(marketLocation()).AddDate(0, 0, 1).Format("2006-01-02")
+
+	for segment, prices := range state.Prices {
+		current := priceAt(prices, now)
+		if current == nil {
+			continue
+		}
+
+		m.mu.Lock()
+		changed := force || !m.currentFrom[segment].Equal(current.From)
+		m.currentFrom[segment] = current.From
+		m.mu.Unlock()
+		if !changed {
+			continue
+		}
+
+		m.announcePrices(segment, current.PerUnit)
+		m.publish(m.topic("prices", segment, "current"), current)
+		if next := priceAt(prices, current.Till); next != nil {
+			m.publish(m.topic("prices", segment, "next"), next)
+		}
+		m.publish(m.topic("prices", segment, "today"), newPriceDay(today, prices))
+		m.publish(m.topic("prices", segment, "tomorrow"), newPriceDay(tomorrow, prices))
+	}
+}
+
+// haDevice groups entities into a device in Home Assistant
+type haDevice struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Manufacturer string   `json:"manufacturer,omitempty"`
+	Model        string   `json:"model,omitempty"`
+}
+
+// haEntity is a Home Assistant MQTT discovery config of a sensor or binary sensor
+type haEntity struct {
+	component string
+
+	Name                string    `json:"name"`
+	UniqueID            string    `json:"unique_id"`
+	StateTopic          string    `json:"state_topic"`
+	ValueTemplate       string    `json:"value_template"`
+	JSONAttributesTopic string    `json:"json_attributes_topic,omitempty"`
+	UnitOfMeasurement   string    `json:"unit_of_measurement,omitempty"`
+	DeviceClass         string    `json:"device_class,omitempty"`
+	StateClass          string    `json:"state_class,omitempty"`
+	Icon                string    `json:"icon,omitempty"`
+	AvailabilityTopic   string    `json:"availability_topic"`
+	Device              *haDevice `json:"device"`
+}
+
+// announce publishes discovery configs that weren't published on this connection yet
+func (m *mqttPublisher) announce(device *haDevice, entities ...haEntity) {
+	if !m.discovery {
+		return
+	}
+
+	for _, e := range entities {
+		e.Device = device
+		e.AvailabilityTopic = m.topic("status")
+		if e.component == "" {
+			e.component = "sensor"
+		}
+		topic := fmt.Sprintf("%s/%s/%s/config", m.discoveryPrefix, e.component, e.UniqueID)
+
+		m.mu.Lock()
+		done := m.announced[topic]
+		m.announced[topic] = true
+		m.mu.Unlock()
+		if !done {
+			m.publish(topic, e)
+		}
+	}
+}
+
+func (m *mqttPublisher) announcePrices(segment, perUnit string) {
+	unit := "EUR/kWh"
+	if strings.EqualFold(perUnit, "M3") {
+		unit = "EUR/m³"
+	}
+	label := strings.ToUpper(segment[:1]) + segment[1:]
+
+	device := &haDevice{
+		Identifiers:  []string{"frankie_prices"},
+		Name:         "Frank Energie prices",
+		Manufacturer: "Frank Energie",
+	}
+	id := "frankie_" + segment + "_price"
+	m.announce(device,
+		haEntity{
+			Name:                label + " price",
+			UniqueID:            id + "_current",
+			StateTopic:          m.topic("prices", segment, "current"),
+			ValueTemplate:       "{{ value_json.allInPrice }}",
+			JSONAttributesTopic: m.topic("prices", segment, "current"),
+			UnitOfMeasurement:   unit,
+			StateClass:          "measurement",
+			Icon:                "mdi:currency-eur",
+		},
+		haEntity{
+			Name:                label + " price next",
+			UniqueID:            id + "_next",
+			StateTopic:          m.topic("prices", segment, "next"),
+			ValueTemplate:       "{{ value_json.allInPrice }}",
+			JSONAttributesTopic: m.topic("prices", segment, "next"),
+			UnitOfMeasurement:   unit,
+			Icon:                "mdi:currency-eur",
+		},
+		haEntity{
+			Name:                label + " price today",
+			UniqueID:            id + "_today",
+			StateTopic:          m.topic("prices", segment, "today"),
+			ValueTemplate:       "{{ value_json.average }}",
+			JSONAttributesTopic: m.topic("prices", segment, "today"),
+			UnitOfMeasurement:   unit,
+			Icon:                "mdi:chart-line",
+		},
+		haEntity{
+			Name:                label + " price tomorrow",
+			UniqueID:            id + "_tomorrow",
+			StateTopic:          m.topic("prices", segment, "tomorrow"),
+			ValueTemplate:       "{{ value_json.average }}",
+			JSONAttributesTopic: m.topic("prices", segment, "tomorrow"),
+			UnitOfMeasurement:   unit,
+			Icon:                "mdi:chart-line",
+		},
+	)
+}
+
+func (m *mqttPublisher) announceSite(id string, site models.Site) {
+	device := &haDevice{
+		Identifiers:  []string{"frankie_site_" + id},
+		Name:         "Frank Energie " + site.Reference,
+		Manufacturer: "Frank Energie",
+		Model:        site.PropositionType,
+	}
+	summary := m.topic("sites", id, "summary")
+	usage := m.topic("sites", id, "usage")
+	uid := "frankie_site_" + id
+	m.announce(device,
+		haEntity{
+			Name:                "Month costs",
+			UniqueID:            uid + "_month_costs",
+			StateTopic:          summary,
+			ValueTemplate:       "{{ value_json.actualCostsUntilLastMeterReadingDate }}",
+			JSONAttributesTopic: summary,
+			UnitOfMeasurement:   "EUR",
+			DeviceClass:         "monetary",
+		},
+		haEntity{
+			Name:              "Month expected costs",
+			UniqueID:          uid + "_month_expected_costs",
+			StateTopic:        summary,
+			ValueTemplate:     "{{ value_json.expectedCosts }}",
+			UnitOfMeasurement: "EUR",
+			DeviceClass:       "monetary",
+		},
+		haEntity{
+			Name:              "Electricity usage last day",
+			UniqueID:          uid + "_electricity_usage",
+			StateTopic:        usage,
+			ValueTemplate:     "{{ value_json.electricity.usageTotal if value_json.electricity else none }}",
+			UnitOfMeasurement: "kWh",
+			DeviceClass:       "energy",
+			StateClass:        "total_increasing",
+		},
+		haEntity{
+			Name:              "Gas usage last day",
+			UniqueID:          uid + "_gas_usage",
+			StateTopic:        usage,
+			ValueTemplate:     "{{ value_json.gas.usageTotal if value_json.gas else none }}",
+			UnitOfMeasurement: "m³",
+			DeviceClass:       "gas",
+			StateClass:        "total_increasing",
+		},
+	)
+}
+
+func (m *mqttPublisher) announceBattery(id string, battery models.SmartBattery) {
+	device := &haDevice{
+		Identifiers:  []string{"frankie_battery_" + id},
+		Name:         battery.Brand + " battery",
+		Manufacturer: battery.Brand,
+		Model:        battery.Provider,
+	}
+	state := m.topic("batteries", id, "state")
+	uid := "frankie_battery_" + id
+	m.announce(device,
+		haEntity{
+			Name:              "State of charge",
+			UniqueID:          uid + "_state_of_charge",
+			StateTopic:        state,
+			ValueTemplate:     "{{ value_json.summary.lastKnownStateOfCharge if value_json.summary else none }}",
+			UnitOfMeasurement: "%",
+			DeviceClass:       "battery",
+			StateClass:        "measurement",
+		},
+		haEntity{
+			Name:              "Trading result",
+			UniqueID:          uid + "_total_result",
+			StateTopic:        state,
+			ValueTemplate:     "{{ value_json.summary.totalResult if value_json.summary else none }}",
+			UnitOfMeasurement: "EUR",
+			DeviceClass:       "monetary",
+		},
+	)
+}
+
+// announceChargeState announces the sensors of a charger or vehicle
+func (m *mqttPublisher) announceChargeState(kind, id, label string, info *models.DeviceInfo) {
+	device := &haDevice{
+		Identifiers: []string{"frankie_" + kind + "_" + id},
+		Name:        label,
+	}
+	if info != nil {
+		device.Name = strings.TrimSpace(info.Brand + " " + info.Model)
+		device.Manufacturer = info.Brand
+		device.Model = info.Model
+	}
+
+	state := m.topic(kind, id, "state")
+	uid := "frankie_" + kind + "_" + id
+	m.announce(device,
+		haEntity{
+			Name:              "Battery level",
+			UniqueID:          uid + "_battery_level",
+			StateTopic:        state,
+			ValueTemplate:     "{{ value_json.chargeState.batteryLevel if value_json.chargeState else none }}",
+			UnitOfMeasurement: "%",
+			DeviceClass:       "battery",
+			StateClass:        "measurement",
+		},
+		haEntity{
+			Name:              "Charge rate",
+			UniqueID:          uid + "_charge_rate",
+			StateTopic:        state,
+			ValueTemplate:     "{{ value_json.chargeState.chargeRate if value_json.chargeState else none }}",
+			UnitOfMeasurement: "kW",
+			DeviceClass:       "power",
+			StateClass:        "measurement",
+		},
+		haEntity{
+			Name:              "Range",
+			UniqueID:          uid + "_range",
+			StateTopic:        state,
+			ValueTemplate:     "{{ value_json.chargeState.range if value_json.chargeState else none }}",
+			UnitOfMeasurement: "km",
+			DeviceClass:       "distance",
+		},
+		haEntity{
+			component:     "binary_sensor",
+			Name:          "Charging",
+			UniqueID:      uid + "_charging",
+			StateTopic:    state,
+			ValueTemplate: "{{ 'ON' if value_json.chargeState and value_json.chargeState.isCharging else 'OFF' }}",
+			DeviceClass:   "battery_charging",
+		},
+		haEntity{
+			component:     "binary_sensor",
+			Name:          "Plugged in",
+			UniqueID:      uid + "_plugged_in",
+			StateTopic:    state,
+			ValueTemplate: "{{ 'ON' if value_json.chargeState and value_json.chargeState.isPluggedIn else 'OFF' }}",
+			DeviceClass:   "plug",
+		},
+	)
+}
+
+// topicID converts an identifier (such as a site reference) into a topic level
+func topicID(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
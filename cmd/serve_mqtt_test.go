@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	broker "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/mochi-mqtt/server/v2/packets"
+
+	"github.com/pietern/frankie/internal/models"
+)
+
+// testBroker is an in-process MQTT broker that records published messages
+type testBroker struct {
+	url string
+
+	mu       sync.Mutex
+	messages map[string][]byte
+}
+
+func startTestBroker(t *testing.T) *testBroker {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	server := broker.New(&broker.Options{
+		InlineClient: true,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err := server.AddHook(new(auth.AllowHook), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.AddListener(listeners.NewTCP(listeners.Config{ID: "test", Address: addr})); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Serve(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	b := &testBroker{url: "tcp://" + addr, messages: make(map[string][]byte)}
+	err = server.Subscribe("#", 1, func(_ *broker.Client, _ packets.Subscription, pk packets.Packet) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.messages[pk.TopicName] = append([]byte(nil), pk.Payload...)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// waitFor waits until all topics were published and returns their last payloads
+func (b *testBroker) waitFor(t *testing.T, topics ...string) map[string][]byte {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		b.mu.Lock()
+		missing := ""
+		for _, topic := range topics {
+			if _, ok := b.messages[topic]; !ok {
+				missing = topic
+				break
+			}
+		}
+		messages := make(map[string][]byte, len(b.messages))
+		for topic, payload := range b.messages {
+			messages[topic] = payload
+		}
+		b.mu.Unlock()
+
+		if missing == "" {
+			return messages
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", missing)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// testLiveState returns a state with a price interval around now, a site and a charger
+func testLiveState() liveState {
+	now := time.Now().Truncate(time.Hour)
+	return liveState{
+		Prices: map[string][]models.Price{
+			"electricity": {
+				{From: now, Till: now.Add(time.Hour), AllInPrice: 0.25, PerUnit: "KWH"},
+				{From: now.Add(time.Hour), Till: now.Add(2 * time.Hour), AllInPrice: 0.30, PerUnit: "KWH"},
+			},
+		},
+		Sites: []siteState{{
+			Site:      models.Site{Reference: "1234AB 5"},
+			Summary:   &models.MonthSummary{ActualCostsUntilLastMeterReadingDate: 42.5, ExpectedCosts: 80},
+			UsageDate: "2026-10-17",
+			Usage: &models.PeriodUsageAndCosts{
+				Electricity: &models.EnergyCategory{UsageTotal: 7.5, Unit: "KWH"},
+			},
+		}},
+		Chargers: []models.EnodeCharger{{
+			ID:          "c-1",
+			Information: &models.DeviceInfo{Brand: "Zaptec", Model: "Go"},
+			ChargeState: &models.ChargeState{BatteryLevel: 80, IsCharging: true},
+		}},
+	}
+}
+
+func TestMQTTPublisher(t *testing.T) {
+	tests := []struct {
+		name      string
+		discovery bool
+	}{
+		{name: "with discovery", discovery: true},
+		{name: "without discovery", discovery: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := startTestBroker(t)
+
+			pub := &mqttPublisher{
+				prefix:          "frankie",
+				discoveryPrefix: "homeassistant",
+				discovery:       tt.discovery,
+				poller:          &poller{state: testLiveState()},
+			}
+			pub.client = mqtt.NewClient(pub.clientOptions(b.url, "frankie-test"))
+			token := pub.client.Connect()
+			if !token.WaitTimeout(5*time.Second) || token.Error() != nil {
+				t.Fatalf("failed to connect: %v", token.Error())
+			}
+			t.Cleanup(func() { pub.client.Disconnect(0) })
+
+			// Connecting publishes the current state
+			stateTopics := []string{
+				"frankie/status",
+				"frankie/prices/electricity/current",
+				"frankie/prices/electricity/next",
+				"frankie/prices/electricity/today",
+				"frankie/prices/electricity/tomorrow",
+				"frankie/sites/1234ab_5/summary",
+				"frankie/sites/1234ab_5/usage",
+				"frankie/chargers/c_1/state",
+			}
+			discoveryTopics := []string{
+				"homeassistant/sensor/frankie_electricity_price_current/config",
+				"homeassistant/sensor/frankie_site_1234ab_5_month_costs/config",
+				"homeassistant/sensor/frankie_site_1234ab_5_electricity_usage/config",
+				"homeassistant/sensor/frankie_chargers_c_1_battery_level/config",
+				"homeassistant/binary_sensor/frankie_chargers_c_1_charging/config",
+			}
+			want := stateTopics
+			if tt.discovery {
+				want = append(want, discoveryTopics...)
+			}
+			messages := b.waitFor(t, want...)
+
+			if got := string(messages["frankie/status"]); got != "online" {
+				t.Errorf("status = %q, want online", got)
+			}
+
+			var price models.Price
+			decode(t, messages["frankie/prices/electricity/current"], &price)
+			if price.AllInPrice != 0.25 {
+				t.Errorf("current price = %v, want 0.25", price.AllInPrice)
+			}
+			decode(t, messages["frankie/prices/electricity/next"], &price)
+			if price.AllInPrice != 0.30 {
+				t.Errorf("next price = %v, want 0.30", price.AllInPrice)
+			}
+
+			var usage UsageDay
+			decode(t, messages["frankie/sites/1234ab_5/usage"], &usage)
+			if usage.Date != "2026-10-17" || usage.PeriodUsageAndCosts == nil || usage.Electricity == nil || usage.Electricity.UsageTotal != 7.5 {
+				t.Errorf("usage = %s", messages["frankie/sites/1234ab_5/usage"])
+			}
+
+			var charger models.EnodeCharger
+			decode(t, messages["frankie/chargers/c_1/state"], &charger)
+			if charger.ChargeState == nil || !charger.ChargeState.IsCharging {
+				t.Errorf("charger state = %s", messages["frankie/chargers/c_1/state"])
+			}
+
+			if !tt.discovery {
+				for topic := range messages {
+					if strings.HasPrefix(topic, "homeassistant/") {
+						t.Errorf("unexpected discovery config %s", topic)
+					}
+				}
+				return
+			}
+
+			var energy map[string]interface{}
+			decode(t, messages["homeassistant/sensor/frankie_site_1234ab_5_electricity_usage/config"], &energy)
+			wantEnergy := map[string]interface{}{
+				"state_topic":         "frankie/sites/1234ab_5/usage",
+				"unit_of_measurement": "kWh",
+				"device_class":        "energy",
+				"state_class":         "total_increasing",
+				"availability_topic":  "frankie/status",
+			}
+			for key, value := range wantEnergy {
+				if energy[key] != value {
+					t.Errorf("energy sensor %s = %v, want %v", key, energy[key], value)
+				}
+			}
+
+			var charging map[string]interface{}
+			decode(t, messages["homeassistant/binary_sensor/frankie_chargers_c_1_charging/config"], &charging)
+			device, _ := charging["device"].(map[string]interface{})
+			if device["manufacturer"] != "Zaptec" || device["model"] != "Go" {
+				t.Errorf("charger device = %v", charging["device"])
+			}
+		})
+	}
+}
+
+func decode(t *testing.T, data []byte, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("failed to decode %s: %v", data, err)
+	}
+}
@@ -3,24 +3,59 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"slices"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
 
+	"github.com/charmbracelet/huh"
 	"github.com/spf13/cobra"
 
 	"github.com/pietern/frankie/internal/api"
+	"github.com/pietern/frankie/internal/auth"
+	"github.com/pietern/frankie/internal/config"
 	"github.com/pietern/frankie/internal/models"
 	"github.com/pietern/frankie/internal/output"
 )
 
+var (
+	sitesSegment string
+	sitesStatus  string
+	sitesAll     bool
+)
+
 var sitesCmd = &cobra.Command{
 	Use:   "sites",
 	Short: "List user sites",
-	Long:  `Display all sites (delivery addresses) linked to your account.`,
-	RunE:  runSites,
+	Long: `Display the sites (delivery addresses) linked to your account. Sites whose
+delivery has ended are only shown with --all.
+
+Commands that take --site accept a full site reference, the start of one
+(such as a postal code), or part of the address (such as the street or
+city). Sites whose delivery has ended only match their full reference. When
+several sites match in a terminal, you can pick one.`,
+	Args: cobra.NoArgs,
+	RunE: runSites,
+}
+
+var sitesUseCmd = &cobra.Command{
+	Use:   "use [site]",
+	Short: "Set the site used by default",
+	Long: `Set the site used by default by commands that take --site, for the active
+profile. Without an argument, pick one of your sites.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeSiteArg,
+	RunE:              runSitesUse,
 }
 
 func init() {
 	rootCmd.AddCommand(sitesCmd)
+	sitesCmd.AddCommand(sitesUseCmd)
+	sitesCmd.Flags().StringVar(&sitesSegment, "segment", "", "only show sites with this segment (electricity or gas)")
+	sitesCmd.Flags().StringVar(&sitesStatus, "status", "", "only show sites with this status (e.g. IN_DELIVERY)")
+	sitesCmd.Flags().BoolVarP(&sitesAll, "all", "a", false, "include sites whose delivery has ended")
 }
 
 func runSites(cmd *cobra.Command, args []string) error {
@@ -29,28 +64,28 @@ func runSites(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	resp, err := client.Execute(api.UserSitesQuery, "UserSites", nil)
+	sites, err := fetchSites(client)
 	if err != nil {
-		return fmt.Errorf("failed to fetch sites: %w", err)
+		return err
 	}
+	filter := siteFilter{Segment: sitesSegment, Status: sitesStatus, IncludeEnded: sitesAll}
+	sites = filter.apply(sites)
 
-	var result models.UserSitesResponse
-	if err := json.Unmarshal(resp.Data, &result); err != nil {
-		return fmt.Errorf("failed to parse response: %w", err)
+	if isStructuredOutput() {
+		return renderOutput(sites, sites)
 	}
 
-	sites := result.UserSites
-
 	if len(sites) == 0 {
 		fmt.Println("No sites found")
 		return nil
 	}
 
-	if getOutputFormat() == "json" {
-		return output.JSON(sites)
+	defaultSite := ""
+	if value, err := config.GetSetting(config.SettingSite); err == nil {
+		defaultSite = value.Value
 	}
 
-	headers := []string{"Reference", "Address", "Status", "Segments", "Start", "Last Reading"}
+	headers := []string{"", "Reference", "Address", "Status", "Segments", "Start", "Last Reading"}
 	var rows [][]string
 
 	for _, site := range sites {
@@ -71,7 +106,13 @@ func runSites(cmd *cobra.Command, args []string) error {
 			lastReading = formatDate(site.LastMeterReadingDate)
 		}
 
+		marker := ""
+		if site.Reference == defaultSite {
+			marker = "*"
+		}
+
 		rows = append(rows, []string{
+			marker,
 			site.Reference,
 			address,
 			site.Status,
@@ -86,6 +127,251 @@ func runSites(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runSitesUse(cmd *cobra.Command, args []string) error {
+	client, err := newAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+	sites, err := fetchSites(client)
+	if err != nil {
+		return err
+	}
+
+	partial := ""
+	if len(args) > 0 {
+		partial = args[0]
+	}
+	site, err := selectSite(sites, partial, siteFilter{})
+	if err != nil {
+		return err
+	}
+
+	path := config.GetProfileSettingsPath(config.GetProfile())
+	if err := config.SetSetting(path, config.SettingSite, site.Reference); err != nil {
+		return err
+	}
+	fmt.Printf("Using site %s by default\n", siteLabel(site))
+	if os.Getenv("FRANKIE_SITE") != "" {
+		fmt.Println("Note: $FRANKIE_SITE is set and takes precedence")
+	}
+	return nil
+}
+
+// siteFilter selects the sites that a site reference can match
+type siteFilter struct {
+	// Segment is a segment the site must have, such as electricity or gas
+	Segment string
+	// Status is the status the site must have, such as IN_DELIVERY
+	Status string
+	// IncludeEnded includes sites whose delivery has ended
+	IncludeEnded bool
+}
+
+// apply returns the sites that pass the filter
+func (f siteFilter) apply(sites []models.Site) []models.Site {
+	today := time.Now().Format("2006-01-02")
+	var selected []models.Site
+	for _, site := range sites {
+		if !f.IncludeEnded && site.DeliveryEndDate != "" && formatDate(site.DeliveryEndDate) < today {
+			continue
+		}
+		if f.Status != "" && !strings.EqualFold(site.Status, f.Status) {
+			continue
+		}
+		if f.Segment != "" && !slices.ContainsFunc(site.Segments, func(s string) bool { return strings.EqualFold(s, f.Segment) }) {
+			continue
+		}
+		selected = append(selected, site)
+	}
+	return selected
+}
+
+// resolveSiteReference resolves a partial site reference to the full reference
+// of an active site, asking which site is meant if several match in a terminal
+func resolveSiteReference(client *api.Client, partial string) (string, error) {
+	return resolveSite(client, partial, siteFilter{})
+}
+
+// resolveSite resolves a partial site reference to the full reference of a
+// site passing the filter
+func resolveSite(client *api.Client, partial string, filter siteFilter) (string, error) {
+	manager := auth.NewManager(client)
+	if err := manager.EnsureAuthenticated(); err != nil {
+		return "", fmt.Errorf("not logged in: %w", err)
+	}
+
+	sites, err := fetchSites(client)
+	if err != nil {
+		return "", err
+	}
+	site, err := selectSite(sites, partial, filter)
+	if err != nil {
+		return "", err
+	}
+	return site.Reference, nil
+}
+
+// selectSite finds the site matching a partial reference, showing a picker
+// when several sites match and there is a terminal to ask in
+func selectSite(sites []models.Site, partial string, filter siteFilter) (models.Site, error) {
+	matches, err := matchSites(sites, partial, filter)
+	if err != nil {
+		return models.Site{}, err
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stderr) {
+		return models.Site{}, ambiguousSiteError(partial, matches)
+	}
+
+	var options []huh.Option[int]
+	for i, site := range matches {
+		options = append(options, huh.NewOption(siteLabel(site), i))
+	}
+	var choice int
+	picker := huh.NewSelect[int]().
+		Title("Select a site").
+		Options(options...).
+		Value(&choice)
+	if err := huh.NewForm(huh.NewGroup(picker)).WithOutput(os.Stderr).Run(); err != nil {
+		return models.Site{}, fmt.Errorf("site selection cancelled: %w", err)
+	}
+	return matches[choice], nil
+}
+
+// matchSiteReference finds the single site matching a partial site reference
+func matchSiteReference(sites []models.Site, partial string) (string, error) {
+	matches, err := matchSites(sites, partial, siteFilter{})
+	if err != nil {
+		return "", err
+	}
+	if len(matches) > 1 {
+		return "", ambiguousSiteError(partial, matches)
+	}
+	return matches[0].Reference, nil
+}
+
+// matchSites returns the sites matching a partial site reference. It matches:
+// - Full reference (exact match, also for sites that fail the filter)
+// - Start of the reference, such as the postal code (e.g., "8147RJ")
+// - Part of the address, such as the street or city (e.g., "Dorpsstraat")
+// An empty reference matches every site that passes the filter. An error is
+// returned if nothing matches.
+func matchSites(sites []models.Site, partial string, filter siteFilter) ([]models.Site, error) {
+	if len(sites) == 0 {
+		return nil, fmt.Errorf("no sites found")
+	}
+
+	key := siteMatchKey(partial)
+	for _, site := range sites {
+		if key != "" && siteMatchKey(site.Reference) == key {
+			return []models.Site{site}, nil
+		}
+	}
+
+	candidates := filter.apply(sites)
+	if key == "" {
+		if len(candidates) == 0 {
+			return nil, fmt.Errorf("no active sites found")
+		}
+		return candidates, nil
+	}
+
+	var matches []models.Site
+	for _, site := range candidates {
+		if strings.HasPrefix(siteMatchKey(site.Reference), key) {
+			matches = append(matches, site)
+			continue
+		}
+		if site.Address == nil {
+			continue
+		}
+		for _, line := range site.Address.AddressFormatted {
+			if strings.Contains(siteMatchKey(line), key) {
+				matches = append(matches, site)
+				break
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no site found matching '%s'", partial)
+	}
+	return matches, nil
+}
+
+// siteMatchKey normalizes a reference or address for matching, ignoring case,
+// spaces and punctuation
+func siteMatchKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, s)
+}
+
+// ambiguousSiteError lists the sites matching a reference
+func ambiguousSiteError(partial string, matches []models.Site) error {
+	var labels []string
+	for _, site := range matches {
+		labels = append(labels, siteLabel(site))
+	}
+	if partial == "" {
+		return fmt.Errorf("you have %d sites, select one with --site or 'frankie sites use': %s", len(matches), strings.Join(labels, "; "))
+	}
+	return fmt.Errorf("ambiguous site reference '%s' matches %d sites: %s", partial, len(matches), strings.Join(labels, "; "))
+}
+
+// siteLabel describes a site by its reference and address
+func siteLabel(site models.Site) string {
+	if site.Address != nil && site.Address.FormattedAddress() != "" {
+		return fmt.Sprintf("%s (%s)", site.Reference, site.Address.FormattedAddress())
+	}
+	return site.Reference
+}
+
+func fetchSites(client *api.Client) ([]models.Site, error) {
+	resp, err := client.Execute(api.UserSitesQuery, "UserSites", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sites: %w", err)
+	}
+
+	var result models.UserSitesResponse
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse sites: %w", err)
+	}
+
+	return result.UserSites, nil
+}
+
+// maxSiteRequests limits how many sites are fetched at the same time
+const maxSiteRequests = 4
+
+// forEachSite calls fn for every site concurrently. fn gets the index of the
+// site, so it can store its result in a slice without locking. The first
+// error in site order is returned.
+func forEachSite(sites []models.Site, fn func(i int, site models.Site) error) error {
+	errs := make([]error, len(sites))
+	sem := make(chan struct{}, maxSiteRequests)
+	var wg sync.WaitGroup
+	for i, site := range sites {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			errs[i] = fn(i, site)
+		})
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("site %s: %w", sites[i].Reference, err)
+		}
+	}
+	return nil
+}
+
 // formatDate formats an ISO date string to a shorter format
 func formatDate(isoDate string) string {
 	if len(isoDate) >= 10 {
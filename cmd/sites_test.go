@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pietern/frankie/internal/models"
+)
+
+func TestMatchSites(t *testing.T) {
+	sites := []models.Site{
+		{
+			Reference: "1234AB 1",
+			Address:   &models.SiteAddress{AddressFormatted: []string{"Hoofdstraat 1", "1234 AB Amsterdam"}},
+			Segments:  []string{"ELECTRICITY", "GAS"},
+		},
+		{
+			Reference: "1234AB 12",
+			Address:   &models.SiteAddress{AddressFormatted: []string{"Hoofdstraat 12", "1234 AB Amsterdam"}},
+			Segments:  []string{"ELECTRICITY"},
+		},
+		{
+			Reference:       "4321ZZ 5",
+			Address:         &models.SiteAddress{AddressFormatted: []string{"Kerkweg 5", "4321 ZZ Utrecht"}},
+			Segments:        []string{"ELECTRICITY"},
+			DeliveryEndDate: "2020-01-01",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		sites   []models.Site
+		partial string
+		filter  siteFilter
+		want    []string
+		wantErr string
+	}{
+		{name: "no sites", partial: "1234", wantErr: "no sites found"},
+		{name: "empty selects active sites", sites: sites, want: []string{"1234AB 1", "1234AB 12"}},
+		{name: "exact reference", sites: sites, partial: "1234AB 1", want: []string{"1234AB 1"}},
+		{name: "exact reference ignores case and spaces", sites: sites, partial: "1234ab1", want: []string{"1234AB 1"}},
+		{name: "exact reference of an ended site", sites: sites, partial: "4321ZZ-5", want: []string{"4321ZZ 5"}},
+		{name: "reference prefix", sites: sites, partial: "1234", want: []string{"1234AB 1", "1234AB 12"}},
+		{name: "address", sites: sites, partial: "hoofdstraat 12", want: []string{"1234AB 12"}},
+		{name: "city", sites: sites, partial: "amsterdam", want: []string{"1234AB 1", "1234AB 12"}},
+		{name: "ended sites are skipped", sites: sites, partial: "utrecht", wantErr: "no site found matching 'utrecht'"},
+		{name: "ended sites are included", sites: sites, partial: "utrecht", filter: siteFilter{IncludeEnded: true}, want: []string{"4321ZZ 5"}},
+		{name: "segment", sites: sites, partial: "1234", filter: siteFilter{Segment: "gas"}, want: []string{"1234AB 1"}},
+		{name: "no active sites", sites: sites[2:], wantErr: "no active sites found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := matchSites(tt.sites, tt.partial, tt.filter)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, site := range matches {
+				got = append(got, site.Reference)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
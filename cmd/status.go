@@ -27,6 +27,7 @@ func init() {
 
 // StatusInfo holds the status information for JSON output
 type StatusInfo struct {
+	Profile      string     `json:"profile"`
 	LoggedIn     bool       `json:"logged_in"`
 	Email        string     `json:"email,omitempty"`
 	TokenExpiry  *time.Time `json:"token_expiry,omitempty"`
@@ -48,8 +49,9 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	email := extractEmailFromToken(creds.AuthToken)
 	expired := auth.IsTokenExpired(creds.AuthToken, 0)
 
-	if getOutputFormat() == "json" {
+	if isStructuredOutput() {
 		info := StatusInfo{
+			Profile:      config.GetProfile(),
 			LoggedIn:     !expired,
 			Email:        email,
 			TokenExpired: expired,
@@ -57,13 +59,15 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		if !expiry.IsZero() {
 			info.TokenExpiry = &expiry
 		}
-		return output.JSON(info)
+		return renderOutput(info, info)
 	}
 
 	// Table output
 	if expired {
 		fmt.Println("Session expired")
 		fmt.Println()
+		fmt.Printf("Profile: %s\n", profileDescription())
+		fmt.Println()
 		fmt.Println("Run 'frankie login' to authenticate.")
 		return nil
 	}
@@ -71,8 +75,10 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	fmt.Println("Logged in")
 	fmt.Println()
 
-	keys := []string{"Email", "Token", "Expiry"}
-	pairs := map[string]string{}
+	keys := []string{"Profile", "Email", "Token", "Expiry"}
+	pairs := map[string]string{
+		"Profile": profileDescription(),
+	}
 
 	if email != "" {
 		pairs["Email"] = email
@@ -88,18 +94,34 @@ func runStatus(cmd *cobra.Command, args []string) error {
 }
 
 func showNotLoggedIn() error {
-	if getOutputFormat() == "json" {
-		return output.JSON(StatusInfo{LoggedIn: false})
+	if isStructuredOutput() {
+		info := StatusInfo{Profile: config.GetProfile(), LoggedIn: false}
+		return renderOutput(info, info)
 	}
 
 	fmt.Println("Not logged in")
 	fmt.Println()
-	fmt.Printf("Credentials file: %s\n", config.GetCredentialsPath())
+	fmt.Printf("Profile: %s\n", profileDescription())
+	fmt.Printf("Credentials: %s\n", auth.CredentialsLocation())
 	fmt.Println()
 	fmt.Println("Run 'frankie login' to authenticate.")
 	return nil
 }
 
+// profileDescription returns the active profile and how it was selected
+func profileDescription() string {
+	name, source := config.GetProfileWithSource()
+	switch source {
+	case config.ProfileSourceFlag:
+		return name + " (from --profile)"
+	case config.ProfileSourceEnv:
+		return fmt.Sprintf("%s (from $%s)", name, config.ProfileEnv)
+	case config.ProfileSourceCurrent:
+		return name + " (from 'frankie profiles use')"
+	}
+	return name
+}
+
 // extractEmailFromToken attempts to extract email from JWT claims
 func extractEmailFromToken(token string) string {
 	parts := strings.Split(token, ".")
@@ -7,11 +7,15 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/pietern/frankie/internal/api"
+	"github.com/pietern/frankie/internal/config"
 	"github.com/pietern/frankie/internal/models"
 	"github.com/pietern/frankie/internal/output"
 )
 
-var summarySite string
+var (
+	summarySite     string
+	summaryAllSites bool
+)
 
 var summaryCmd = &cobra.Command{
 	Use:   "summary",
@@ -23,6 +27,10 @@ var summaryCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(summaryCmd)
 	summaryCmd.Flags().StringVarP(&summarySite, "site", "s", "", "site reference (optional if you have one site)")
+	bindSetting(summaryCmd.Flags(), "site", config.SettingSite)
+	summaryCmd.Flags().BoolVar(&summaryAllSites, "all-sites", false, "show every active site and their total")
+	summaryCmd.MarkFlagsMutuallyExclusive("site", "all-sites")
+	bindSiteFlag(summaryCmd, "site")
 }
 
 func runSummary(cmd *cobra.Command, args []string) error {
@@ -31,34 +39,27 @@ func runSummary(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if summaryAllSites {
+		return runSummaryAllSites(client)
+	}
+
 	// Resolve site reference
 	siteRef, err := resolveSiteReference(client, summarySite)
 	if err != nil {
 		return err
 	}
 
-	variables := map[string]interface{}{
-		"siteReference": siteRef,
-	}
-
-	resp, err := client.Execute(api.MonthSummaryQuery, "MonthSummary", variables)
+	summary, err := fetchMonthSummary(client, siteRef)
 	if err != nil {
-		return fmt.Errorf("failed to fetch summary: %w", err)
-	}
-
-	var result models.MonthSummaryResponse
-	if err := json.Unmarshal(resp.Data, &result); err != nil {
-		return fmt.Errorf("failed to parse response: %w", err)
+		return err
 	}
-
-	summary := result.MonthSummary
 	if summary == nil {
 		fmt.Println("No summary data available")
 		return nil
 	}
 
-	if getOutputFormat() == "json" {
-		return output.JSON(summary)
+	if isStructuredOutput() {
+		return renderOutput(summary, summary)
 	}
 
 	// Display summary
@@ -89,3 +90,104 @@ func runSummary(cmd *cobra.Command, args []string) error {
 	output.KeyValueOrdered(keys, pairs)
 	return nil
 }
+
+// SiteSummary is the month summary of one site
+type SiteSummary struct {
+	SiteReference string `json:"siteReference"`
+	*models.MonthSummary
+}
+
+// SummaryTotal is the sum of the month summaries of all sites
+type SummaryTotal struct {
+	ActualCostsUntilLastMeterReadingDate   float64 `json:"actualCostsUntilLastMeterReadingDate"`
+	ExpectedCostsUntilLastMeterReadingDate float64 `json:"expectedCostsUntilLastMeterReadingDate"`
+	ExpectedCosts                          float64 `json:"expectedCosts"`
+}
+
+// AllSitesSummary is the month summary of every site
+type AllSitesSummary struct {
+	Sites []SiteSummary `json:"sites"`
+	Total SummaryTotal  `json:"total"`
+}
+
+// runSummaryAllSites shows the month summary of every site, one row per site
+func runSummaryAllSites(client *api.Client) error {
+	sites, err := fetchSites(client)
+	if err != nil {
+		return err
+	}
+
+	sites = siteFilter{}.apply(sites)
+
+	summaries := make([]*models.MonthSummary, len(sites))
+	err = forEachSite(sites, func(i int, site models.Site) error {
+		summary, err := fetchMonthSummary(client, site.Reference)
+		summaries[i] = summary
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	var result AllSitesSummary
+	for i, site := range sites {
+		summary := summaries[i]
+		result.Sites = append(result.Sites, SiteSummary{SiteReference: site.Reference, MonthSummary: summary})
+		if summary == nil {
+			continue
+		}
+		result.Total.ActualCostsUntilLastMeterReadingDate += summary.ActualCostsUntilLastMeterReadingDate
+		result.Total.ExpectedCostsUntilLastMeterReadingDate += summary.ExpectedCostsUntilLastMeterReadingDate
+		result.Total.ExpectedCosts += summary.ExpectedCosts
+	}
+
+	if isStructuredOutput() {
+		return renderOutput(result, result.Sites)
+	}
+
+	headers := []string{"Site", "Actual Costs", "Expected (to date)", "Expected (month)", "Last Reading", "Completeness"}
+	var rows [][]string
+	for _, s := range result.Sites {
+		if s.MonthSummary == nil {
+			rows = append(rows, []string{s.SiteReference, "-", "-", "-", "-", "-"})
+			continue
+		}
+		rows = append(rows, []string{
+			s.SiteReference,
+			fmt.Sprintf("€%.2f", s.ActualCostsUntilLastMeterReadingDate),
+			fmt.Sprintf("€%.2f", s.ExpectedCostsUntilLastMeterReadingDate),
+			fmt.Sprintf("€%.2f", s.ExpectedCosts),
+			formatDate(s.LastMeterReadingDate),
+			fmt.Sprintf("%.0f%%", s.MeterReadingDayCompleteness*100),
+		})
+	}
+	rows = append(rows, []string{
+		"Total",
+		fmt.Sprintf("€%.2f", result.Total.ActualCostsUntilLastMeterReadingDate),
+		fmt.Sprintf("€%.2f", result.Total.ExpectedCostsUntilLastMeterReadingDate),
+		fmt.Sprintf("€%.2f", result.Total.ExpectedCosts),
+		"",
+		"",
+	})
+
+	output.Table(headers, rows)
+	return nil
+}
+
+func fetchMonthSummary(client *api.Client, siteRef string) (*models.MonthSummary, error) {
+	variables := map[string]interface{}{
+		"siteReference": siteRef,
+	}
+
+	resp, err := client.Execute(api.MonthSummaryQuery, "MonthSummary", variables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch summary: %w", err)
+	}
+
+	var result models.MonthSummaryResponse
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result.MonthSummary, nil
+}
@@ -0,0 +1,527 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pietern/frankie/internal/api"
+	"github.com/pietern/frankie/internal/config"
+	"github.com/pietern/frankie/internal/models"
+	"github.com/pietern/frankie/internal/output"
+	"github.com/pietern/frankie/internal/store"
+)
+
+const (
+	datasetPrices          = "prices"
+	datasetSitePrices      = "site-prices"
+	datasetUsage           = "usage"
+	datasetInvoices        = "invoices"
+	datasetSummaries       = "summaries"
+	datasetBatterySessions = "battery-sessions"
+
+	// backfillFirst backfills each dataset down to the first meter reading date
+	backfillFirst = "first"
+
+	// syncInitialDays is how far back a dataset is synced the first time
+	syncInitialDays = 7
+
+	// batterySessionChunkDays limits the range of a single sessions query
+	batterySessionChunkDays = 31
+)
+
+var allDatasets = []string{
+	datasetPrices,
+	datasetSitePrices,
+	datasetUsage,
+	datasetInvoices,
+	datasetSummaries,
+	datasetBatterySessions,
+}
+
+var (
+	dbPath           string
+	syncBackfillFrom string
+	syncDatasets     []string
+	syncResolution   int
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Download history into the local database",
+	Long: `Incrementally download prices, usage and costs, invoices, month summaries
+and battery sessions into a local SQLite database.
+
+The last synced date is tracked per dataset, so subsequent runs only fetch
+what is new. Use --backfill-from to fetch older history; it is limited to
+each site's first meter reading date. Pass "first" to backfill everything.
+
+Examples:
+  frankie sync
+  frankie sync --datasets prices,usage
+  frankie sync --backfill-from 2024-01-01
+  frankie sync --backfill-from first`,
+	RunE: runSync,
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().StringVar(&dbPath, "db", "", "database path (default: history.db in the data directory)")
+	syncCmd.Flags().StringVar(&syncBackfillFrom, "backfill-from", "", "backfill history from this date (YYYY-MM-DD or 'first')")
+	syncCmd.Flags().StringSliceVar(&syncDatasets, "datasets", allDatasets, "datasets to sync")
+	syncCmd.Flags().IntVarP(&syncResolution, "resolution", "r", resolution60Min, "public price resolution in minutes (15 or 60)")
+	bindSetting(syncCmd.Flags(), "resolution", config.SettingResolution)
+}
+
+// SyncResult describes what was synced for a single dataset and key
+type SyncResult struct {
+	Dataset string `json:"dataset"`
+	Key     string `json:"key"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Records int    `json:"records"`
+}
+
+// syncer downloads datasets into the local store
+type syncer struct {
+	client       *api.Client
+	store        *store.Store
+	backfillFrom string
+	resolution   string
+	results      []SyncResult
+
+	// ctx is checked between requests, so a sync can be stopped
+	ctx context.Context
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	for _, dataset := range syncDatasets {
+		if !isDataset(dataset) {
+			return fmt.Errorf("unknown dataset: %s (must be one of %s)", dataset, strings.Join(allDatasets, ", "))
+		}
+	}
+
+	if syncBackfillFrom != "" && syncBackfillFrom != backfillFirst {
+		if _, err := time.Parse("2006-01-02", syncBackfillFrom); err != nil {
+			return fmt.Errorf("invalid backfill date '%s' (expected YYYY-MM-DD or '%s')", syncBackfillFrom, backfillFirst)
+		}
+	}
+
+	resolution, err := priceResolution(syncResolution)
+	if err != nil {
+		return err
+	}
+
+	client, err := newAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+
+	db, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	s := &syncer{
+		client:       client,
+		store:        db,
+		backfillFrom: syncBackfillFrom,
+		resolution:   resolution,
+	}
+
+	if err := s.run(cmd.Context(), syncDatasets); err != nil {
+		return err
+	}
+
+	if isStructuredOutput() {
+		return renderOutput(s.results, s.results)
+	}
+
+	if len(s.results) == 0 {
+		fmt.Println("Everything is up to date")
+		return nil
+	}
+
+	headers := []string{"Dataset", "Key", "From", "To", "Records"}
+	var rows [][]string
+	for _, r := range s.results {
+		rows = append(rows, []string{r.Dataset, r.Key, r.From, r.To, fmt.Sprintf("%d", r.Records)})
+	}
+
+	output.Table(headers, rows)
+	return nil
+}
+
+// openStore opens the local history database
+func openStore() (*store.Store, error) {
+	path := dbPath
+	if path == "" {
+		path = databasePath()
+	}
+	return store.Open(path)
+}
+
+// databasePath returns the path of the history database of the active
+// profile, first moving it from where older versions kept it
+func databasePath() string {
+	path := config.GetDatabasePath()
+	for _, oldPath := range config.GetLegacyDatabasePaths(config.GetProfile()) {
+		if oldPath == path {
+			continue
+		}
+		moved, err := store.Move(oldPath, path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Warning:", err)
+		} else if moved {
+			fmt.Fprintf(os.Stderr, "Moved history database from %s to %s\n", oldPath, path)
+			break
+		}
+	}
+	return path
+}
+
+func isDataset(name string) bool {
+	for _, d := range allDatasets {
+		if d == name {
+			return true
+		}
+	}
+	return false
+}
+
+// run syncs the given datasets for all sites. It stops early when ctx is done.
+func (s *syncer) run(ctx context.Context, datasets []string) error {
+	s.ctx = ctx
+	enabled := make(map[string]bool)
+	for _, d := range datasets {
+		enabled[d] = true
+	}
+
+	sites, err := fetchSites(s.client)
+	if err != nil {
+		return err
+	}
+
+	// The earliest first meter reading bounds backfills of account-wide datasets
+	earliest := ""
+	for _, site := range sites {
+		first := formatDate(site.FirstMeterReadingDate)
+		if first != "" && (earliest == "" || first < earliest) {
+			earliest = first
+		}
+	}
+
+	if enabled[datasetPrices] {
+		if err := s.syncPrices(earliest); err != nil {
+			return err
+		}
+	}
+
+	for _, site := range sites {
+		if err := s.ctx.Err(); err != nil {
+			return err
+		}
+		if enabled[datasetSitePrices] {
+			if err := s.syncSitePrices(site); err != nil {
+				return err
+			}
+		}
+		if enabled[datasetUsage] {
+			if err := s.syncUsage(site); err != nil {
+				return err
+			}
+		}
+		if enabled[datasetInvoices] {
+			if err := s.syncInvoices(site); err != nil {
+				return err
+			}
+		}
+		if enabled[datasetSummaries] {
+			if err := s.syncSummary(site); err != nil {
+				return err
+			}
+		}
+	}
+
+	if enabled[datasetBatterySessions] {
+		if err := s.syncBatterySessions(earliest); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// startDate determines where syncing a dataset should resume.
+// The last synced date is fetched again, since it may have been incomplete.
+func (s *syncer) startDate(dataset, key, earliest string) (string, error) {
+	start := ""
+	switch s.backfillFrom {
+	case "":
+		last, err := s.store.LastSynced(dataset, key)
+		if err != nil {
+			return "", err
+		}
+		start = last
+		if start == "" {
+			start = time.Now().AddDate(0, 0, -syncInitialDays).Format("2006-01-02")
+		}
+	case backfillFirst:
+		start = earliest
+		if start == "" {
+			start = time.Now().AddDate(0, 0, -syncInitialDays).Format("2006-01-02")
+		}
+	default:
+		start = s.backfillFrom
+	}
+
+	if earliest != "" && start < earliest {
+		start = earliest
+	}
+	return start, nil
+}
+
+// syncDaily fetches a dataset day by day, recording progress after each day
+// so an interrupted sync resumes where it left off.
+func (s *syncer) syncDaily(dataset, key, earliest, end string, fetch func(date string) (int, error)) error {
+	start, err := s.startDate(dataset, key, earliest)
+	if err != nil {
+		return err
+	}
+	if start > end {
+		return nil
+	}
+
+	dates, err := datesBetween(start, end)
+	if err != nil {
+		return err
+	}
+
+	result := SyncResult{Dataset: dataset, Key: key, From: start}
+	for _, date := range dates {
+		if err := s.ctx.Err(); err != nil {
+			s.addResult(result)
+			return err
+		}
+		n, err := fetch(date)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			// Nothing available for this date (yet)
+			continue
+		}
+		if err := s.store.SetLastSynced(dataset, key, date); err != nil {
+			return err
+		}
+		result.To = date
+		result.Records += n
+	}
+
+	s.addResult(result)
+	return nil
+}
+
+// addResult records the result of syncing a dataset, if anything was synced
+func (s *syncer) addResult(result SyncResult) {
+	if result.Records > 0 {
+		s.results = append(s.results, result)
+	}
+}
+
+func (s *syncer) syncPrices(earliest string) error {
+	// Prices of each resolution are synced separately
+	return s.syncDaily(datasetPrices, "NL/"+s.resolution, earliest, latestPriceDate(), func(date string) (int, error) {
+		prices, err := fetchPublicPrices(s.client, date, s.resolution)
+		if err != nil {
+			return 0, err
+		}
+		return s.savePrices("", prices)
+	})
+}
+
+func (s *syncer) syncSitePrices(site models.Site) error {
+	earliest := formatDate(site.FirstMeterReadingDate)
+	return s.syncDaily(datasetSitePrices, site.Reference, earliest, latestPriceDate(), func(date string) (int, error) {
+		prices, err := fetchCustomerPrices(s.client, date, site.Reference)
+		if err != nil {
+			return 0, err
+		}
+		return s.savePrices(site.Reference, prices)
+	})
+}
+
+func (s *syncer) savePrices(siteRef string, prices *models.MarketPrices) (int, error) {
+	if prices == nil {
+		return 0, nil
+	}
+	if err := s.store.SavePrices(siteRef, "electricity", prices.ElectricityPrices); err != nil {
+		return 0, err
+	}
+	if err := s.store.SavePrices(siteRef, "gas", prices.GasPrices); err != nil {
+		return 0, err
+	}
+	return len(prices.ElectricityPrices) + len(prices.GasPrices), nil
+}
+
+func (s *syncer) syncUsage(site models.Site) error {
+	// Usage is only complete up to the last meter reading
+	end := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	if last := formatDate(site.LastMeterReadingDate); last != "" && last < end {
+		end = last
+	}
+
+	earliest := formatDate(site.FirstMeterReadingDate)
+	return s.syncDaily(datasetUsage, site.Reference, earliest, end, func(date string) (int, error) {
+		usage, err := fetchUsage(s.client, site.Reference, date)
+		if err != nil {
+			return 0, err
+		}
+		if usage == nil {
+			return 0, nil
+		}
+		if err := s.store.SaveUsage(site.Reference, usage); err != nil {
+			return 0, err
+		}
+		n := 0
+		for _, category := range []*models.EnergyCategory{usage.Electricity, usage.Gas, usage.FeedIn} {
+			if category != nil {
+				n += len(category.Items)
+			}
+		}
+		return n, nil
+	})
+}
+
+func (s *syncer) syncInvoices(site models.Site) error {
+	invoices, err := fetchInvoices(s.client, site.Reference)
+	if err != nil {
+		return err
+	}
+	if invoices == nil || len(invoices.AllInvoices) == 0 {
+		return nil
+	}
+
+	if err := s.store.SaveInvoices(site.Reference, invoices.AllInvoices); err != nil {
+		return err
+	}
+
+	today := time.Now().Format("2006-01-02")
+	if err := s.store.SetLastSynced(datasetInvoices, site.Reference, today); err != nil {
+		return err
+	}
+
+	first := invoices.AllInvoices[0].StartDate
+	last := first
+	for _, inv := range invoices.AllInvoices {
+		if inv.StartDate < first {
+			first = inv.StartDate
+		}
+		if inv.StartDate > last {
+			last = inv.StartDate
+		}
+	}
+
+	s.results = append(s.results, SyncResult{
+		Dataset: datasetInvoices,
+		Key:     site.Reference,
+		From:    formatDate(first),
+		To:      formatDate(last),
+		Records: len(invoices.AllInvoices),
+	})
+	return nil
+}
+
+func (s *syncer) syncSummary(site models.Site) error {
+	summary, err := fetchMonthSummary(s.client, site.Reference)
+	if err != nil {
+		return err
+	}
+	if summary == nil {
+		return nil
+	}
+
+	date := formatDate(summary.LastMeterReadingDate)
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return fmt.Errorf("invalid last meter reading date: %s", summary.LastMeterReadingDate)
+	}
+	month := day.Format("2006-01")
+
+	if err := s.store.SaveMonthSummary(site.Reference, month, summary); err != nil {
+		return err
+	}
+	if err := s.store.SetLastSynced(datasetSummaries, site.Reference, date); err != nil {
+		return err
+	}
+
+	s.results = append(s.results, SyncResult{
+		Dataset: datasetSummaries,
+		Key:     site.Reference,
+		From:    month,
+		To:      month,
+		Records: 1,
+	})
+	return nil
+}
+
+func (s *syncer) syncBatterySessions(earliest string) error {
+	batteries, err := fetchBatteries(s.client)
+	if err != nil {
+		if errors.Is(err, api.ErrSmartTradingNotEnabled) {
+			return nil
+		}
+		return err
+	}
+
+	today := time.Now().Format("2006-01-02")
+	for _, battery := range batteries {
+		start, err := s.startDate(datasetBatterySessions, battery.ID, earliest)
+		if err != nil {
+			return err
+		}
+
+		result := SyncResult{Dataset: datasetBatterySessions, Key: battery.ID, From: start}
+		for chunkStart := start; chunkStart <= today; {
+			if err := s.ctx.Err(); err != nil {
+				s.addResult(result)
+				return err
+			}
+			t, _ := time.Parse("2006-01-02", chunkStart)
+			chunkEnd := t.AddDate(0, 0, batterySessionChunkDays-1).Format("2006-01-02")
+			if chunkEnd > today {
+				chunkEnd = today
+			}
+
+			sessions, err := fetchBatterySessions(s.client, battery.ID, chunkStart, chunkEnd)
+			if err != nil {
+				return err
+			}
+			if sessions != nil && len(sessions.Sessions) > 0 {
+				if err := s.store.SaveBatterySessions(battery.ID, sessions.Sessions); err != nil {
+					return err
+				}
+				result.Records += len(sessions.Sessions)
+				result.To = chunkEnd
+			}
+			if err := s.store.SetLastSynced(datasetBatterySessions, battery.ID, chunkEnd); err != nil {
+				return err
+			}
+
+			chunkStart = t.AddDate(0, 0, batterySessionChunkDays).Format("2006-01-02")
+		}
+
+		s.addResult(result)
+	}
+
+	return nil
+}
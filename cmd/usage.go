@@ -7,15 +7,30 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/pietern/frankie/internal/analysis"
 	"github.com/pietern/frankie/internal/api"
+	"github.com/pietern/frankie/internal/config"
 	"github.com/pietern/frankie/internal/models"
 	"github.com/pietern/frankie/internal/output"
 )
 
 var (
-	usageSite  string
-	usageDate  string
-	usageType  string
+	usageSite     string
+	usageDate     string
+	usageType     string
+	usageFrom     string
+	usageTo       string
+	usageAllSites bool
+)
+
+var (
+	anomaliesFrom       string
+	anomaliesTo         string
+	anomaliesNightStart int
+	anomaliesNightEnd   int
+	anomaliesWindow     int
+	anomaliesThreshold  float64
+	anomaliesIntervals  bool
 )
 
 var usageCmd = &cobra.Command{
@@ -25,11 +40,48 @@ var usageCmd = &cobra.Command{
 	RunE:  runUsage,
 }
 
+var usageAnomaliesCmd = &cobra.Command{
+	Use:   "anomalies",
+	Short: "Detect baseload increases and usage anomalies",
+	Long: `Estimate the night-time baseload for each day in a date range and flag days
+and intervals that deviate significantly from the rolling baseline of the
+preceding days. Reports the estimated wasted usage and costs.
+
+Examples:
+  frankie usage anomalies
+  frankie usage anomalies --from 2025-01-01 --to 2025-01-31
+  frankie usage anomalies --night-start 0 --night-end 6 --threshold 2.5
+  frankie usage anomalies -o csv --intervals`,
+	RunE: runUsageAnomalies,
+}
+
 func init() {
 	rootCmd.AddCommand(usageCmd)
-	usageCmd.Flags().StringVarP(&usageSite, "site", "s", "", "site reference (optional if you have one site)")
+	usageCmd.AddCommand(usageAnomaliesCmd)
+	usageCmd.PersistentFlags().StringVarP(&usageSite, "site", "s", "", "site reference (optional if you have one site)")
+	bindSetting(usageCmd.PersistentFlags(), "site", config.SettingSite)
 	usageCmd.Flags().StringVarP(&usageDate, "date", "d", "", "date (YYYY-MM-DD, default: today)")
-	usageCmd.Flags().StringVarP(&usageType, "type", "t", "", "type: electricity, gas, or feedin (default: all)")
+	usageCmd.Flags().StringVar(&usageFrom, "from", "", "first date of a range (YYYY-MM-DD)")
+	usageCmd.Flags().StringVar(&usageTo, "to", "", "last date of a range (YYYY-MM-DD, default: yesterday)")
+	usageCmd.Flags().BoolVar(&usageAllSites, "all-sites", false, "show every active site and their total")
+	usageCmd.MarkFlagsMutuallyExclusive("site", "all-sites")
+	usageCmd.MarkFlagsMutuallyExclusive("date", "from")
+	usageCmd.PersistentFlags().StringVarP(&usageType, "type", "t", "", "type: electricity, gas, or feedin (default: all)")
+	bindSiteFlag(usageCmd, "site")
+	bindDateFlag(usageCmd, usageCmd.Flags(), "date")
+	bindDateFlag(usageCmd, usageCmd.Flags(), "from")
+	bindDateFlag(usageCmd, usageCmd.Flags(), "to")
+
+	defaults := analysis.DefaultOptions()
+	usageAnomaliesCmd.Flags().StringVar(&anomaliesFrom, "from", "", "start date (YYYY-MM-DD, default: 14 days ago)")
+	usageAnomaliesCmd.Flags().StringVar(&anomaliesTo, "to", "", "end date (YYYY-MM-DD, default: yesterday)")
+	usageAnomaliesCmd.Flags().IntVar(&anomaliesNightStart, "night-start", defaults.NightStart, "first hour of the night window")
+	usageAnomaliesCmd.Flags().IntVar(&anomaliesNightEnd, "night-end", defaults.NightEnd, "hour at which the night window ends")
+	usageAnomaliesCmd.Flags().IntVar(&anomaliesWindow, "window", defaults.Window, "number of preceding days in the rolling baseline")
+	usageAnomaliesCmd.Flags().Float64Var(&anomaliesThreshold, "threshold", defaults.Threshold, "deviations from the baseline before flagging")
+	usageAnomaliesCmd.Flags().BoolVar(&anomaliesIntervals, "intervals", false, "write the anomalous intervals as records instead of the days (csv, tsv, ndjson, template or --columns)")
+	bindDateFlag(usageAnomaliesCmd, usageAnomaliesCmd.Flags(), "from")
+	bindDateFlag(usageAnomaliesCmd, usageAnomaliesCmd.Flags(), "to")
 }
 
 func runUsage(cmd *cobra.Command, args []string) error {
@@ -38,18 +90,330 @@ func runUsage(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Resolve site reference
-	siteRef, err := resolveSiteReference(client, usageSite)
+	if usageAllSites {
+		return runUsageAllSites(client)
+	}
+
+	// Resolve site reference, among the sites that have the requested type
+	siteRef, err := resolveSite(client, usageSite, siteFilter{Segment: usageSegment(usageType)})
 	if err != nil {
 		return err
 	}
 
+	if usageFrom != "" {
+		return runUsageRange(client, siteRef)
+	}
+
 	// Set date
 	date := usageDate
 	if date == "" {
 		date = time.Now().Format("2006-01-02")
 	}
 
+	usage, err := fetchUsage(client, siteRef, date)
+	if err != nil {
+		return err
+	}
+	if usage == nil {
+		fmt.Println("No usage data available")
+		return nil
+	}
+
+	if isStructuredOutput() {
+		source, err := newUsageSource(client, siteRef)
+		if err != nil {
+			return err
+		}
+		return renderOutput(usage, usageRecords(usage, usageType, source))
+	}
+
+	// Display based on type filter
+	switch usageType {
+	case "electricity", "elec", "e":
+		displayEnergyUsage("Electricity", usage.Electricity, date)
+	case "gas", "g":
+		displayEnergyUsage("Gas", usage.Gas, date)
+	case "feedin", "feed", "f":
+		displayEnergyUsage("Feed-in", usage.FeedIn, date)
+	default:
+		// Show summary of all
+		displayUsageSummary(usage, date)
+	}
+
+	return nil
+}
+
+// UsageDay holds the usage and costs of a single date in a range
+type UsageDay struct {
+	Date string `json:"date"`
+	*models.PeriodUsageAndCosts
+}
+
+// runUsageRange shows usage for every date in a range, one row per date
+func runUsageRange(client *api.Client, siteRef string) error {
+	to := usageTo
+	if to == "" {
+		to = time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	}
+
+	dates, err := datesBetween(usageFrom, to)
+	if err != nil {
+		return err
+	}
+
+	var source usageSource
+	if isStructuredOutput() {
+		source, err = newUsageSource(client, siteRef)
+		if err != nil {
+			return err
+		}
+	}
+
+	var days []UsageDay
+	for _, date := range dates {
+		usage, err := fetchUsage(client, siteRef, date)
+		if err != nil {
+			return err
+		}
+		if usage == nil {
+			continue
+		}
+
+		// Stream each day as soon as it is fetched
+		if isStreamingOutput() {
+			if err := streamRecords(usageRecords(usage, usageType, source)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		days = append(days, UsageDay{Date: date, PeriodUsageAndCosts: usage})
+	}
+
+	if isStreamingOutput() {
+		return nil
+	}
+
+	if isStructuredOutput() {
+		var records []UsageRecord
+		for _, day := range days {
+			records = append(records, usageRecords(day.PeriodUsageAndCosts, usageType, source)...)
+		}
+		return renderOutput(days, records)
+	}
+
+	if len(days) == 0 {
+		fmt.Println("No usage data available")
+		return nil
+	}
+
+	fmt.Printf("Usage from %s to %s\n\n", usageFrom, to)
+
+	headers := []string{"Date", "Electricity", "Gas", "Feed-in", "Costs"}
+	var rows [][]string
+	total := 0.0
+
+	for _, day := range days {
+		cells := []string{day.Date}
+		costs := 0.0
+		for _, category := range []*models.EnergyCategory{day.Electricity, day.Gas, day.FeedIn} {
+			if category == nil {
+				cells = append(cells, "-")
+				continue
+			}
+			cells = append(cells, fmt.Sprintf("%.2f %s", category.UsageTotal, category.Unit))
+			costs += category.CostsTotal
+		}
+		cells = append(cells, fmt.Sprintf("€%.2f", costs))
+		rows = append(rows, cells)
+		total += costs
+	}
+
+	output.Table(headers, rows)
+	fmt.Printf("Total costs: €%.2f\n", total)
+	return nil
+}
+
+// UsageTotal is the total usage and costs over a period
+type UsageTotal struct {
+	Electricity float64 `json:"electricity"`
+	Gas         float64 `json:"gas"`
+	FeedIn      float64 `json:"feedIn"`
+	Costs       float64 `json:"costs"`
+}
+
+// add adds the usage and costs of a day
+func (t *UsageTotal) add(usage *models.PeriodUsageAndCosts) {
+	if usage.Electricity != nil {
+		t.Electricity += usage.Electricity.UsageTotal
+		t.Costs += usage.Electricity.CostsTotal
+	}
+	if usage.Gas != nil {
+		t.Gas += usage.Gas.UsageTotal
+		t.Costs += usage.Gas.CostsTotal
+	}
+	if usage.FeedIn != nil {
+		t.FeedIn += usage.FeedIn.UsageTotal
+		t.Costs += usage.FeedIn.CostsTotal
+	}
+}
+
+// SiteUsage is the usage of one site over a period
+type SiteUsage struct {
+	SiteReference string     `json:"siteReference"`
+	Days          []UsageDay `json:"days"`
+	Total         UsageTotal `json:"total"`
+}
+
+// AllSitesUsage is the usage of every site over a period
+type AllSitesUsage struct {
+	From  string      `json:"from"`
+	To    string      `json:"to"`
+	Sites []SiteUsage `json:"sites"`
+	Total UsageTotal  `json:"total"`
+}
+
+// runUsageAllSites shows the usage of every site on a date or over a range,
+// one row per site
+func runUsageAllSites(client *api.Client) error {
+	from := usageDate
+	if from == "" {
+		from = time.Now().Format("2006-01-02")
+	}
+	to := from
+	if usageFrom != "" {
+		from, to = usageFrom, usageTo
+		if to == "" {
+			to = time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+		}
+	}
+	dates, err := datesBetween(from, to)
+	if err != nil {
+		return err
+	}
+	typeName, err := usageTypeName(usageType)
+	if err != nil {
+		return err
+	}
+
+	sites, err := fetchSites(client)
+	if err != nil {
+		return err
+	}
+	// Like a single site, only active sites with the requested type are shown
+	sites = siteFilter{Segment: usageSegment(usageType)}.apply(sites)
+
+	result := AllSitesUsage{From: from, To: to, Sites: make([]SiteUsage, len(sites))}
+	records := make([][]UsageRecord, len(sites))
+	err = forEachSite(sites, func(i int, site models.Site) error {
+		var source usageSource
+		if isStructuredOutput() {
+			var err error
+			source, err = newUsageSource(client, site.Reference)
+			if err != nil {
+				return err
+			}
+		}
+
+		siteUsage := SiteUsage{SiteReference: site.Reference}
+		for _, date := range dates {
+			usage, err := fetchUsage(client, site.Reference, date)
+			if err != nil {
+				return err
+			}
+			if usage == nil {
+				continue
+			}
+			// The table and totals only include the requested type, like the records
+			usage = usageOfType(usage, typeName)
+			siteUsage.Days = append(siteUsage.Days, UsageDay{Date: date, PeriodUsageAndCosts: usage})
+			siteUsage.Total.add(usage)
+			records[i] = append(records[i], usageRecords(usage, usageType, source)...)
+		}
+		result.Sites[i] = siteUsage
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Units are taken from the data, as they are not the same for every segment
+	units := map[string]string{}
+	for _, s := range result.Sites {
+		result.Total.Electricity += s.Total.Electricity
+		result.Total.Gas += s.Total.Gas
+		result.Total.FeedIn += s.Total.FeedIn
+		result.Total.Costs += s.Total.Costs
+		for _, day := range s.Days {
+			for name, category := range map[string]*models.EnergyCategory{"electricity": day.Electricity, "gas": day.Gas, "feedin": day.FeedIn} {
+				if category != nil && category.Unit != "" {
+					units[name] = category.Unit
+				}
+			}
+		}
+	}
+
+	if isStructuredOutput() {
+		var all []UsageRecord
+		for _, r := range records {
+			all = append(all, r...)
+		}
+		return renderOutput(result, all)
+	}
+
+	if from == to {
+		fmt.Printf("Usage for %s\n\n", from)
+	} else {
+		fmt.Printf("Usage from %s to %s\n\n", from, to)
+	}
+
+	// Only the requested type gets a column
+	columns := []struct {
+		name   string
+		header string
+		value  func(UsageTotal) float64
+	}{
+		{"electricity", "Electricity", func(t UsageTotal) float64 { return t.Electricity }},
+		{"gas", "Gas", func(t UsageTotal) float64 { return t.Gas }},
+		{"feedin", "Feed-in", func(t UsageTotal) float64 { return t.FeedIn }},
+	}
+	headers := []string{"Site"}
+	for _, c := range columns {
+		if typeName == "" || c.name == typeName {
+			headers = append(headers, c.header)
+		}
+	}
+	headers = append(headers, "Costs")
+
+	formatTotal := func(t UsageTotal) []string {
+		var cells []string
+		for _, c := range columns {
+			if typeName == "" || c.name == typeName {
+				cells = append(cells, fmt.Sprintf("%.2f %s", c.value(t), units[c.name]))
+			}
+		}
+		return append(cells, fmt.Sprintf("€%.2f", t.Costs))
+	}
+
+	var rows [][]string
+	for _, s := range result.Sites {
+		if len(s.Days) == 0 {
+			row := []string{s.SiteReference}
+			for range headers[1:] {
+				row = append(row, "-")
+			}
+			rows = append(rows, row)
+			continue
+		}
+		rows = append(rows, append([]string{s.SiteReference}, formatTotal(s.Total)...))
+	}
+	rows = append(rows, append([]string{"Total"}, formatTotal(result.Total)...))
+
+	output.Table(headers, rows)
+	return nil
+}
+
+func fetchUsage(client *api.Client, siteRef, date string) (*models.PeriodUsageAndCosts, error) {
 	variables := map[string]interface{}{
 		"siteReference": siteRef,
 		"date":          date,
@@ -57,40 +421,303 @@ func runUsage(cmd *cobra.Command, args []string) error {
 
 	resp, err := client.Execute(api.PeriodUsageAndCostsQuery, "PeriodUsageAndCosts", variables)
 	if err != nil {
-		return fmt.Errorf("failed to fetch usage: %w", err)
+		return nil, fmt.Errorf("failed to fetch usage: %w", err)
 	}
 
 	var result models.PeriodUsageAndCostsResponse
 	if err := json.Unmarshal(resp.Data, &result); err != nil {
-		return fmt.Errorf("failed to parse response: %w", err)
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	usage := result.PeriodUsageAndCosts
-	if usage == nil {
-		fmt.Println("No usage data available")
-		return nil
+	return result.PeriodUsageAndCosts, nil
+}
+
+// UsageRecord is a single usage interval in CSV/TSV output
+type UsageRecord struct {
+	Type          string `json:"type"`
+	SiteReference string `json:"siteReference"`
+	EAN           string `json:"ean"`
+	models.UsageItem
+}
+
+// InfluxPoint returns the usage interval as a line protocol point
+func (r UsageRecord) InfluxPoint() output.Point {
+	return output.Point{
+		Measurement: "usage",
+		Tags: []output.Tag{
+			{Key: "segment", Value: r.Type},
+			{Key: "siteReference", Value: r.SiteReference},
+			{Key: "ean", Value: r.EAN},
+			{Key: "unit", Value: r.Unit},
+		},
+		Fields: []output.Field{
+			{Key: "usage", Value: r.Usage},
+			{Key: "costs", Value: r.Costs},
+		},
+		Time: parseTimestamp(r.From),
 	}
+}
+
+// usageSource identifies the site (and its meters) that usage belongs to
+type usageSource struct {
+	siteRef string
+	// eans holds the EAN of each usage type
+	eans map[string]string
+}
 
-	if getOutputFormat() == "json" {
-		return output.JSON(usage)
+// newUsageSource looks up the EANs of a site's connections. They are only part
+// of records, so the lookup is skipped when the document is rendered instead.
+func newUsageSource(client *api.Client, siteRef string) (usageSource, error) {
+	if !usesOutputRecords() {
+		return usageSource{siteRef: siteRef}, nil
 	}
+	eans, err := connectionEANs(client, siteRef)
+	if err != nil {
+		return usageSource{}, err
+	}
+	return usageSource{siteRef: siteRef, eans: eans}, nil
+}
 
-	// Display based on type filter
+// usageRecords returns the usage items of the selected categories as records
+func usageRecords(usage *models.PeriodUsageAndCosts, usageType string, source usageSource) []UsageRecord {
+	categories := []struct {
+		name     string
+		category *models.EnergyCategory
+	}{
+		{"electricity", usage.Electricity},
+		{"gas", usage.Gas},
+		{"feedin", usage.FeedIn},
+	}
+
+	var records []UsageRecord
+	for _, c := range categories {
+		if c.category == nil {
+			continue
+		}
+		if usageType != "" {
+			if selected, err := usageCategory(usage, usageType); err != nil || selected != c.category {
+				continue
+			}
+		}
+		for _, item := range c.category.Items {
+			records = append(records, UsageRecord{
+				Type:          c.name,
+				SiteReference: source.siteRef,
+				EAN:           source.eans[c.name],
+				UsageItem:     item,
+			})
+		}
+	}
+	return records
+}
+
+// usageSegment returns the site segment needed for a --type value, or "" for all types
+func usageSegment(usageType string) string {
 	switch usageType {
+	case "electricity", "elec", "e", "feedin", "feed", "f":
+		return "electricity"
+	case "gas", "g":
+		return "gas"
+	}
+	return ""
+}
+
+// usageTypeName returns the name of the type selected by a --type value, such
+// as electricity for e, or "" without one
+func usageTypeName(usageType string) (string, error) {
+	switch usageType {
+	case "":
+		return "", nil
 	case "electricity", "elec", "e":
-		displayEnergyUsage("Electricity", usage.Electricity, date)
+		return "electricity", nil
 	case "gas", "g":
-		displayEnergyUsage("Gas", usage.Gas, date)
+		return "gas", nil
 	case "feedin", "feed", "f":
-		displayEnergyUsage("Feed-in", usage.FeedIn, date)
-	default:
-		// Show summary of all
-		displayUsageSummary(usage, date)
+		return "feedin", nil
 	}
+	return "", fmt.Errorf("invalid type: %s (must be electricity, gas, or feedin)", usageType)
+}
 
+// usageOfType returns the usage with only the category of a type name, or
+// all categories without one
+func usageOfType(usage *models.PeriodUsageAndCosts, typeName string) *models.PeriodUsageAndCosts {
+	if typeName == "" {
+		return usage
+	}
+	selected := &models.PeriodUsageAndCosts{ID: usage.ID}
+	switch typeName {
+	case "electricity":
+		selected.Electricity = usage.Electricity
+	case "gas":
+		selected.Gas = usage.Gas
+	case "feedin":
+		selected.FeedIn = usage.FeedIn
+	}
+	return selected
+}
+
+// usageCategory returns the category selected by a --type value
+func usageCategory(usage *models.PeriodUsageAndCosts, usageType string) (*models.EnergyCategory, error) {
+	switch usageType {
+	case "", "electricity", "elec", "e":
+		return usage.Electricity, nil
+	case "gas", "g":
+		return usage.Gas, nil
+	case "feedin", "feed", "f":
+		return usage.FeedIn, nil
+	}
+	return nil, fmt.Errorf("invalid type: %s (must be electricity, gas, or feedin)", usageType)
+}
+
+func runUsageAnomalies(cmd *cobra.Command, args []string) error {
+	opts := analysis.DefaultOptions()
+	opts.NightStart = anomaliesNightStart
+	opts.NightEnd = anomaliesNightEnd
+	opts.Window = anomaliesWindow
+	opts.Threshold = anomaliesThreshold
+	if opts.NightStart < 0 || opts.NightEnd > 24 || opts.NightStart >= opts.NightEnd {
+		return fmt.Errorf("invalid night window: %d-%d", opts.NightStart, opts.NightEnd)
+	}
+	if opts.Window < 1 {
+		return fmt.Errorf("window must be at least 1 day")
+	}
+	if anomaliesIntervals && !usesOutputRecords() {
+		return fmt.Errorf("--intervals is only supported with output that is written as records")
+	}
+
+	now := time.Now()
+	to := anomaliesTo
+	if to == "" {
+		to = now.AddDate(0, 0, -1).Format("2006-01-02")
+	}
+	from := anomaliesFrom
+	if from == "" {
+		from = now.AddDate(0, 0, -14).Format("2006-01-02")
+	}
+
+	// Fetch preceding days as well so the first days have a baseline
+	start, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return fmt.Errorf("invalid date '%s' (expected YYYY-MM-DD)", from)
+	}
+	end, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return fmt.Errorf("invalid date '%s' (expected YYYY-MM-DD)", to)
+	}
+	if end.Before(start) {
+		return fmt.Errorf("end date %s is before start date %s", to, from)
+	}
+	dates, err := datesBetween(start.AddDate(0, 0, -opts.Window).Format("2006-01-02"), to)
+	if err != nil {
+		return err
+	}
+
+	client, err := newAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+
+	// Resolve site reference, among the sites that have the analysed type
+	segment := usageSegment(usageType)
+	if segment == "" {
+		segment = "electricity"
+	}
+	siteRef, err := resolveSite(client, usageSite, siteFilter{Segment: segment})
+	if err != nil {
+		return err
+	}
+
+	var days []analysis.Day
+	for _, date := range dates {
+		usage, err := fetchUsage(client, siteRef, date)
+		if err != nil {
+			return err
+		}
+		if usage == nil {
+			continue
+		}
+		category, err := usageCategory(usage, usageType)
+		if err != nil {
+			return err
+		}
+		if category == nil || len(category.Items) == 0 {
+			continue
+		}
+		days = append(days, analysis.Day{Date: date, Items: category.Items})
+	}
+
+	report := analysis.Detect(days, from, opts)
+
+	if isStructuredOutput() {
+		if anomaliesIntervals {
+			return renderOutput(report, report.Intervals)
+		}
+		return renderOutput(report, report.Days)
+	}
+
+	if len(report.Days) == 0 {
+		fmt.Println("Not enough usage data to establish a baseline")
+		return nil
+	}
+
+	displayAnomalies(report, from, to)
 	return nil
 }
 
+func displayAnomalies(report *analysis.Report, from, to string) {
+	fmt.Printf("Baseload analysis for %s to %s (%02d:00-%02d:00)\n\n", from, to, anomaliesNightStart, anomaliesNightEnd)
+
+	headers := []string{"Date", "Usage", "Baseload", "Baseline", "Wasted", "Costs", ""}
+	var rows [][]string
+
+	for _, day := range report.Days {
+		flag := ""
+		wasted := "-"
+		costs := "-"
+		if day.Anomalous {
+			flag = "!"
+			wasted = fmt.Sprintf("%.2f %s", day.WastedUsage, report.Unit)
+			costs = fmt.Sprintf("€%.2f", day.WastedCosts)
+		}
+		rows = append(rows, []string{
+			day.Date,
+			fmt.Sprintf("%.2f %s", day.Usage, report.Unit),
+			fmt.Sprintf("%.3f %s/h", day.Baseload, report.Unit),
+			fmt.Sprintf("%.3f %s/h", day.Baseline, report.Unit),
+			wasted,
+			costs,
+			flag,
+		})
+	}
+
+	output.Table(headers, rows)
+
+	if len(report.Intervals) > 0 {
+		fmt.Println("Anomalous intervals")
+
+		headers = []string{"Date", "Time", "Usage", "Expected", "Wasted", "Costs"}
+		rows = nil
+		for _, iv := range report.Intervals {
+			rows = append(rows, []string{
+				iv.Date,
+				formatTime(iv.From),
+				fmt.Sprintf("%.3f %s", iv.Usage, report.Unit),
+				fmt.Sprintf("%.3f %s", iv.Expected, report.Unit),
+				fmt.Sprintf("%.3f %s", iv.WastedUsage, report.Unit),
+				fmt.Sprintf("€%.4f", iv.WastedCosts),
+			})
+		}
+
+		output.Table(headers, rows)
+	}
+
+	if report.WastedUsage > 0 {
+		fmt.Printf("Estimated waste: %.2f %s (€%.2f)\n", report.WastedUsage, report.Unit, report.WastedCosts)
+	} else {
+		fmt.Println("No anomalies found")
+	}
+}
+
 func displayUsageSummary(usage *models.PeriodUsageAndCosts, date string) {
 	fmt.Printf("Usage summary for %s\n\n", date)
 
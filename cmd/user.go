@@ -41,8 +41,8 @@ func runUser(cmd *cobra.Command, args []string) error {
 
 	user := result.Me
 
-	if getOutputFormat() == "json" {
-		return output.JSON(user)
+	if isStructuredOutput() {
+		return renderOutput(user, user)
 	}
 
 	// Build display data
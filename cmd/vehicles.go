@@ -19,8 +19,50 @@ var vehiclesCmd = &cobra.Command{
 	RunE:  runVehicles,
 }
 
+var vehiclesSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Change the smart charging settings of a vehicle",
+	Long: `Change the smart charging settings of a vehicle: the deadline, the deadline
+per weekday, the charge limits and whether smart and solar charging are on.
+Settings that are not given are kept.
+
+The changes are shown and must be confirmed, unless --yes is given. With
+--dry-run, the GraphQL mutation is printed instead of sent.
+
+Examples:
+  frankie vehicles set --deadline 07:30 --max-limit 80 --smart on --solar off
+  frankie vehicles set --id ID --schedule mon=07:00,sat=10:00 --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runChargeSettingsSet(cmd, vehicleKind)
+	},
+}
+
+var vehiclesShowCmd = &cobra.Command{
+	Use:   "show [id]",
+	Short: "Show the details of a vehicle",
+	Long: `Show the status, charge state, smart charging settings and interventions
+of a vehicle, along with how long ago it was last seen. The ID is optional if
+you have one vehicle.
+
+With --watch, the details are refreshed until interrupted.
+
+Examples:
+  frankie vehicles show
+  frankie vehicles show ID --watch --interval 1m`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeFirstArg(completeVehicles),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runChargeShow(cmd, args, vehicleKind)
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(vehiclesCmd)
+	vehiclesCmd.AddCommand(vehiclesShowCmd)
+	vehiclesCmd.AddCommand(vehiclesSetCmd)
+	addChargeShowFlags(vehiclesShowCmd)
+	addChargeSettingsFlags(vehiclesSetCmd, "vehicle", completeVehicles)
 }
 
 func runVehicles(cmd *cobra.Command, args []string) error {
@@ -29,30 +71,24 @@ func runVehicles(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	resp, err := client.Execute(api.EnodeVehiclesQuery, "EnodeVehicles", nil)
+	vehicles, err := fetchVehicles(client)
 	if err != nil {
 		if errors.Is(err, api.ErrSmartChargingNotEnabled) {
 			fmt.Println("Smart charging is not enabled for your account")
 			return nil
 		}
-		return fmt.Errorf("failed to fetch vehicles: %w", err)
+		return err
 	}
 
-	var result models.EnodeVehiclesResponse
-	if err := json.Unmarshal(resp.Data, &result); err != nil {
-		return fmt.Errorf("failed to parse response: %w", err)
+	if isStructuredOutput() {
+		return renderOutput(vehicles, vehicleRecords(vehicles))
 	}
 
-	vehicles := result.EnodeVehicles
 	if len(vehicles) == 0 {
 		fmt.Println("No smart vehicles found")
 		return nil
 	}
 
-	if getOutputFormat() == "json" {
-		return output.JSON(vehicles)
-	}
-
 	headers := []string{"Brand", "Model", "Status", "Battery", "Range", "Charging", "Rate"}
 	var rows [][]string
 
@@ -97,3 +133,35 @@ func runVehicles(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func fetchVehicles(client *api.Client) ([]models.EnodeVehicle, error) {
+	resp, err := client.Execute(api.EnodeVehiclesQuery, "EnodeVehicles", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch vehicles: %w", err)
+	}
+
+	var result models.EnodeVehiclesResponse
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result.EnodeVehicles, nil
+}
+
+// VehicleRecord is a vehicle in CSV/TSV and line protocol output
+type VehicleRecord struct {
+	models.EnodeVehicle
+}
+
+// InfluxPoint returns the charge state of the vehicle as a line protocol point
+func (r VehicleRecord) InfluxPoint() output.Point {
+	return chargeStatePoint("vehicle", r.ID, r.Information, r.ChargeState, r.IsReachable)
+}
+
+func vehicleRecords(vehicles []models.EnodeVehicle) []VehicleRecord {
+	records := make([]VehicleRecord, 0, len(vehicles))
+	for _, v := range vehicles {
+		records = append(records, VehicleRecord{EnodeVehicle: v})
+	}
+	return records
+}
@@ -0,0 +1,261 @@
+package analysis
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/pietern/frankie/internal/models"
+)
+
+const (
+	// madScale converts a median absolute deviation into a standard deviation estimate
+	madScale = 1.4826
+
+	// minHistoryDays is the minimum number of preceding days needed for a baseline
+	minHistoryDays = 3
+
+	// minRelativeSpread keeps tiny deviations on very stable days from being flagged
+	minRelativeSpread = 0.1
+)
+
+// Options controls baseload estimation and anomaly detection
+type Options struct {
+	NightStart int            // first hour of the night window (inclusive)
+	NightEnd   int            // last hour of the night window (exclusive)
+	Window     int            // number of preceding days forming the rolling baseline
+	Threshold  float64        // number of deviations before a value is anomalous
+	Location   *time.Location // timezone used to determine hours of the day
+}
+
+// DefaultOptions returns options suitable for a typical household
+func DefaultOptions() Options {
+	return Options{
+		NightStart: 1,
+		NightEnd:   5,
+		Window:     7,
+		Threshold:  3,
+		Location:   time.Local,
+	}
+}
+
+// Day holds the usage items for a single date
+type Day struct {
+	Date  string
+	Items []models.UsageItem
+}
+
+// DayResult holds the baseload analysis for a single date
+type DayResult struct {
+	Date        string  `json:"date"`
+	Usage       float64 `json:"usage"`
+	Costs       float64 `json:"costs"`
+	Baseload    float64 `json:"baseload"`
+	Baseline    float64 `json:"baseline"`
+	Anomalous   bool    `json:"anomalous"`
+	WastedUsage float64 `json:"wastedUsage"`
+	WastedCosts float64 `json:"wastedCosts"`
+}
+
+// IntervalResult describes a single interval that deviates from its baseline
+type IntervalResult struct {
+	Date        string  `json:"date"`
+	From        string  `json:"from"`
+	Till        string  `json:"till"`
+	Usage       float64 `json:"usage"`
+	Expected    float64 `json:"expected"`
+	WastedUsage float64 `json:"wastedUsage"`
+	WastedCosts float64 `json:"wastedCosts"`
+}
+
+// Report holds the outcome of an anomaly detection run.
+// Baseload and baseline are expressed in units per hour.
+type Report struct {
+	Unit        string           `json:"unit"`
+	Days        []DayResult      `json:"days"`
+	Intervals   []IntervalResult `json:"intervals"`
+	WastedUsage float64          `json:"wastedUsage"`
+	WastedCosts float64          `json:"wastedCosts"`
+}
+
+// interval is a usage item with parsed timestamps
+type interval struct {
+	item  models.UsageItem
+	from  time.Time
+	hours float64
+	slot  string
+}
+
+// Detect estimates the night-time baseload of each day and flags days and
+// intervals that deviate significantly from the rolling baseline formed by
+// the preceding days. Days before from only contribute to the baseline, and
+// days without enough history to form a baseline are left out of the report.
+func Detect(days []Day, from string, opts Options) *Report {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+
+	// Sort a copy, leaving the order of the caller's days alone
+	days = slices.Clone(days)
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+
+	report := &Report{}
+	parsed := make([][]interval, len(days))
+	baseloads := make([]float64, len(days))
+
+	for i, day := range days {
+		parsed[i] = parseIntervals(day.Items, opts.Location)
+		baseloads[i] = nightBaseload(parsed[i], opts)
+		if report.Unit == "" && len(day.Items) > 0 {
+			report.Unit = day.Items[0].Unit
+		}
+	}
+
+	for i, day := range days {
+		if day.Date < from || len(parsed[i]) == 0 {
+			continue
+		}
+
+		result := DayResult{Date: day.Date, Baseload: baseloads[i]}
+		hours := 0.0
+		for _, iv := range parsed[i] {
+			result.Usage += iv.item.Usage
+			result.Costs += iv.item.Costs
+			hours += iv.hours
+		}
+		unitPrice := 0.0
+		if result.Usage > 0 {
+			unitPrice = result.Costs / result.Usage
+		}
+
+		// Rolling baseline over the preceding days with data
+		var history []float64
+		var historyIdx []int
+		for j := i - 1; j >= 0 && len(history) < opts.Window; j-- {
+			if len(parsed[j]) > 0 && !math.IsNaN(baseloads[j]) {
+				history = append(history, baseloads[j])
+				historyIdx = append(historyIdx, j)
+			}
+		}
+		if len(history) < minHistoryDays || math.IsNaN(result.Baseload) {
+			// Not enough data to judge this day
+			continue
+		}
+
+		baseline, spread := medianAndSpread(history)
+		result.Baseline = baseline
+
+		excessRate := 0.0
+		if result.Baseload > baseline+opts.Threshold*spread {
+			result.Anomalous = true
+			excessRate = result.Baseload - baseline
+			result.WastedUsage = excessRate * hours
+			result.WastedCosts = result.WastedUsage * unitPrice
+		}
+
+		// Compare each interval with the same time slot on preceding days
+		slots := make(map[string][]float64)
+		for _, j := range historyIdx {
+			for _, iv := range parsed[j] {
+				slots[iv.slot] = append(slots[iv.slot], iv.item.Usage)
+			}
+		}
+		for _, iv := range parsed[i] {
+			samples := slots[iv.slot]
+			if len(samples) < minHistoryDays {
+				continue
+			}
+			expected, slotSpread := medianAndSpread(samples)
+			expected += excessRate * iv.hours
+			if iv.item.Usage <= expected+opts.Threshold*slotSpread {
+				continue
+			}
+
+			price := unitPrice
+			if iv.item.Usage > 0 {
+				price = iv.item.Costs / iv.item.Usage
+			}
+			wasted := iv.item.Usage - expected
+			report.Intervals = append(report.Intervals, IntervalResult{
+				Date:        day.Date,
+				From:        iv.item.From,
+				Till:        iv.item.Till,
+				Usage:       iv.item.Usage,
+				Expected:    expected,
+				WastedUsage: wasted,
+				WastedCosts: wasted * price,
+			})
+			report.WastedUsage += wasted
+			report.WastedCosts += wasted * price
+		}
+
+		report.WastedUsage += result.WastedUsage
+		report.WastedCosts += result.WastedCosts
+		report.Days = append(report.Days, result)
+	}
+
+	return report
+}
+
+// parseIntervals parses item timestamps, skipping items that cannot be parsed
+func parseIntervals(items []models.UsageItem, loc *time.Location) []interval {
+	var intervals []interval
+	for _, item := range items {
+		from, err := time.Parse(time.RFC3339, item.From)
+		if err != nil {
+			continue
+		}
+		till, err := time.Parse(time.RFC3339, item.Till)
+		if err != nil || !till.After(from) {
+			continue
+		}
+		intervals = append(intervals, interval{
+			item:  item,
+			from:  from.In(loc),
+			hours: till.Sub(from).Hours(),
+			slot:  from.In(loc).Format("15:04"),
+		})
+	}
+	return intervals
+}
+
+// nightBaseload returns the median usage rate (per hour) during the night
+// window, or NaN when the day has no night-time intervals.
+func nightBaseload(intervals []interval, opts Options) float64 {
+	var rates []float64
+	for _, iv := range intervals {
+		hour := iv.from.Hour()
+		if hour >= opts.NightStart && hour < opts.NightEnd {
+			rates = append(rates, iv.item.Usage/iv.hours)
+		}
+	}
+	if len(rates) == 0 {
+		return math.NaN()
+	}
+	return median(rates)
+}
+
+// medianAndSpread returns the median and a robust spread estimate of values
+func medianAndSpread(values []float64) (float64, float64) {
+	m := median(values)
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - m)
+	}
+	spread := median(deviations) * madScale
+	if floor := math.Abs(m) * minRelativeSpread; spread < floor {
+		spread = floor
+	}
+	return m, spread
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
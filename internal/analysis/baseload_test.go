@@ -0,0 +1,189 @@
+package analysis
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pietern/frankie/internal/models"
+)
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   float64
+	}{
+		{"single", []float64{4}, 4},
+		{"odd", []float64{3, 1, 2}, 2},
+		{"even", []float64{4, 1, 3, 2}, 2.5},
+		{"duplicates", []float64{1, 1, 1, 5}, 1},
+		{"negative", []float64{-2, -1, 5}, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := append([]float64(nil), tt.values...)
+			if got := median(values); got != tt.want {
+				t.Errorf("median(%v) = %v, want %v", tt.values, got, tt.want)
+			}
+			for i := range values {
+				if values[i] != tt.values[i] {
+					t.Fatalf("median reordered its input to %v", values)
+				}
+			}
+		})
+	}
+}
+
+func TestMedianAndSpread(t *testing.T) {
+	tests := []struct {
+		name       string
+		values     []float64
+		wantMedian float64
+		wantSpread float64
+	}{
+		// Deviations 1, 0, 1, 8 have a median of 1
+		{"outlier", []float64{1, 2, 3, 10}, 2.5, 1 * madScale},
+		// Deviations of 0 are raised to a tenth of the median
+		{"constant", []float64{0.5, 0.5, 0.5}, 0.5, 0.05},
+		{"zero", []float64{0, 0, 0}, 0, 0},
+		// Deviations 0.2, 0.2, 0 have a median of 0.2
+		{"symmetric", []float64{0.8, 1.2, 1}, 1, 0.2 * madScale},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, spread := medianAndSpread(tt.values)
+			if !approxEqual(m, tt.wantMedian) || !approxEqual(spread, tt.wantSpread) {
+				t.Errorf("medianAndSpread(%v) = %v, %v, want %v, %v", tt.values, m, spread, tt.wantMedian, tt.wantSpread)
+			}
+		})
+	}
+}
+
+// testDay returns a day of hourly usage, with a rate per hour during the
+// default night window and another rate otherwise
+func testDay(date string, nightRate, dayRate float64) Day {
+	start, _ := time.Parse("2006-01-02", date)
+	day := Day{Date: date}
+	for hour := 0; hour < 24; hour++ {
+		from := start.Add(time.Duration(hour) * time.Hour)
+		usage := dayRate
+		if hour >= 1 && hour < 5 {
+			usage = nightRate
+		}
+		day.Items = append(day.Items, models.UsageItem{
+			Date:  date,
+			From:  from.Format(time.RFC3339),
+			Till:  from.Add(time.Hour).Format(time.RFC3339),
+			Usage: usage,
+			Costs: usage * 0.25,
+			Unit:  "KWH",
+		})
+	}
+	return day
+}
+
+func TestDetect(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Location = time.UTC
+
+	steady := []Day{
+		testDay("2026-10-01", 0.2, 0.5),
+		testDay("2026-10-02", 0.2, 0.5),
+		testDay("2026-10-03", 0.2, 0.5),
+	}
+
+	spike := testDay("2026-10-04", 0.2, 0.5)
+	spike.Items[12].Usage, spike.Items[12].Costs = 3, 0.75
+
+	tests := []struct {
+		name string
+		days []Day
+		from string
+		// wantDays holds the reported dates and whether they are anomalous
+		wantDays      map[string]bool
+		wantIntervals int
+		wantWasted    float64
+	}{
+		{
+			name:     "steady",
+			days:     append(steady[:3:3], testDay("2026-10-04", 0.2, 0.5)),
+			from:     "2026-10-01",
+			wantDays: map[string]bool{"2026-10-04": false},
+		},
+		{
+			name:     "baseload increase",
+			days:     append(steady[:3:3], testDay("2026-10-04", 0.6, 0.5)),
+			from:     "2026-10-01",
+			wantDays: map[string]bool{"2026-10-04": true},
+			// 0.4 per hour over 24 hours, and the night intervals are explained by it
+			wantWasted: 0.4 * 24,
+		},
+		{
+			name:          "interval spike",
+			days:          append(steady[:3:3], spike),
+			from:          "2026-10-01",
+			wantDays:      map[string]bool{"2026-10-04": false},
+			wantIntervals: 1,
+			wantWasted:    2.5,
+		},
+		{
+			name:     "before from",
+			days:     append(steady[:3:3], testDay("2026-10-04", 0.6, 0.5)),
+			from:     "2026-10-05",
+			wantDays: map[string]bool{},
+		},
+		{
+			name:     "not enough history",
+			days:     steady[:2],
+			from:     "2026-10-01",
+			wantDays: map[string]bool{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Detect sorts the days without reordering them for the caller
+			days := slices.Clone(tt.days)
+			slices.Reverse(days)
+			report := Detect(days, tt.from, opts)
+			if len(days) > 0 && days[0].Date != tt.days[len(tt.days)-1].Date {
+				t.Errorf("Detect reordered its input")
+			}
+			if report.Unit != "KWH" {
+				t.Errorf("Unit = %q, want KWH", report.Unit)
+			}
+			if len(report.Days) != len(tt.wantDays) {
+				t.Fatalf("got %d days, want %d", len(report.Days), len(tt.wantDays))
+			}
+			for _, day := range report.Days {
+				anomalous, ok := tt.wantDays[day.Date]
+				if !ok {
+					t.Fatalf("unexpected day %s", day.Date)
+				}
+				if day.Anomalous != anomalous {
+					t.Errorf("%s anomalous = %v, want %v", day.Date, day.Anomalous, anomalous)
+				}
+				if !approxEqual(day.Baseline, 0.2) {
+					t.Errorf("%s baseline = %v, want 0.2", day.Date, day.Baseline)
+				}
+			}
+			if len(report.Intervals) != tt.wantIntervals {
+				t.Errorf("got %d intervals, want %d", len(report.Intervals), tt.wantIntervals)
+			}
+			if !approxEqual(report.WastedUsage, tt.wantWasted) {
+				t.Errorf("WastedUsage = %v, want %v", report.WastedUsage, tt.wantWasted)
+			}
+			if !approxEqual(report.WastedCosts, tt.wantWasted*0.25) {
+				t.Errorf("WastedCosts = %v, want %v", report.WastedCosts, tt.wantWasted*0.25)
+			}
+		})
+	}
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
@@ -2,10 +2,14 @@ package api
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -26,8 +30,20 @@ const (
 type Client struct {
 	httpClient *http.Client
 	baseURL    string
-	authToken  string
 	country    string
+
+	mu          sync.RWMutex
+	authToken   string
+	tokenSource func() (string, error)
+
+	cache    Cache
+	cacheTTL time.Duration
+}
+
+// Cache stores raw responses of queries
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
 }
 
 // NewClient creates a new API client
@@ -43,9 +59,39 @@ func NewClient() *Client {
 
 // SetAuthToken sets the authentication token
 func (c *Client) SetAuthToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.authToken = token
 }
 
+// SetTokenSource sets a function that provides the authentication token for
+// every request, for long-running processes that must renew it. It takes
+// precedence over the token set with SetAuthToken.
+func (c *Client) SetTokenSource(source func() (string, error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokenSource = source
+}
+
+// SetCache enables caching of successful query responses for the given
+// duration. Mutations are never cached.
+func (c *Client) SetCache(cache Cache, ttl time.Duration) {
+	c.cache = cache
+	c.cacheTTL = ttl
+}
+
+// token returns the authentication token to send, if any
+func (c *Client) token() (string, error) {
+	c.mu.RLock()
+	source, token := c.tokenSource, c.authToken
+	c.mu.RUnlock()
+
+	if source != nil {
+		return source()
+	}
+	return token, nil
+}
+
 // SetCountry sets the country for API requests (NL or BE)
 func (c *Client) SetCountry(country string) {
 	c.country = country
@@ -95,8 +141,12 @@ func (c *Client) doRequest(query, operationName string, variables map[string]int
 	req.Header.Set("x-graphql-client-os", ClientOS)
 	req.Header.Set("skip-graphcdn", "1")
 
-	if c.authToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.authToken)
+	token, err := c.token()
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrAuthRequired, err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
 	}
 
 	if c.country != "" && c.country != "NL" {
@@ -129,6 +179,17 @@ func (c *Client) ExecuteRaw(query, operationName string, variables map[string]in
 
 // ExecuteWithHeaders sends a GraphQL request with custom headers
 func (c *Client) ExecuteWithHeaders(query, operationName string, variables map[string]interface{}, extraHeaders map[string]string) (*GraphQLResponse, error) {
+	var key string
+	if c.cache != nil && len(extraHeaders) == 0 && !isMutation(query) {
+		key = c.cacheKey(query, operationName, variables)
+		if body, ok := c.cache.Get(key); ok {
+			var gqlResp GraphQLResponse
+			if err := json.Unmarshal(body, &gqlResp); err == nil {
+				return &gqlResp, nil
+			}
+		}
+	}
+
 	body, statusCode, err := c.doRequest(query, operationName, variables, extraHeaders)
 	if err != nil {
 		return nil, err
@@ -157,9 +218,29 @@ func (c *Client) ExecuteWithHeaders(query, operationName string, variables map[s
 		return &gqlResp, c.handleGraphQLErrors(gqlResp.Errors)
 	}
 
+	if key != "" {
+		c.cache.Set(key, body, c.cacheTTL)
+	}
+
 	return &gqlResp, nil
 }
 
+// cacheKey identifies a request in the response cache
+func (c *Client) cacheKey(query, operationName string, variables map[string]interface{}) string {
+	vars, _ := json.Marshal(variables)
+	h := sha256.New()
+	for _, part := range []string{c.country, operationName, query, string(vars)} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// isMutation reports whether a GraphQL document is a mutation
+func isMutation(query string) bool {
+	return strings.HasPrefix(strings.TrimSpace(query), "mutation")
+}
+
 // handleGraphQLErrors processes GraphQL errors and returns appropriate Go errors
 func (c *Client) handleGraphQLErrors(errors []GraphQLError) error {
 	if len(errors) == 0 {
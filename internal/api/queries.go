@@ -452,6 +452,82 @@ query EnodeVehicles {
 }
 `
 
+const UpdateEnodeVehicleChargeSettingsMutation = `
+mutation UpdateEnodeVehicleChargeSettings($vehicleId: String!, $chargeSettings: EnodeChargeSettingsInput!) {
+    updateEnodeVehicleChargeSettings(vehicleId: $vehicleId, chargeSettings: $chargeSettings) {
+        id
+        deadline
+        hourMonday
+        hourTuesday
+        hourWednesday
+        hourThursday
+        hourFriday
+        hourSaturday
+        hourSunday
+        isSmartChargingEnabled
+        isSolarChargingEnabled
+        maxChargeLimit
+        minChargeLimit
+    }
+}
+`
+
+const UpdateEnodeChargerChargeSettingsMutation = `
+mutation UpdateEnodeChargerChargeSettings($chargerId: String!, $chargeSettings: EnodeChargeSettingsInput!) {
+    updateEnodeChargerChargeSettings(chargerId: $chargerId, chargeSettings: $chargeSettings) {
+        id
+        deadline
+        hourMonday
+        hourTuesday
+        hourWednesday
+        hourThursday
+        hourFriday
+        hourSaturday
+        hourSunday
+        isSmartChargingEnabled
+        isSolarChargingEnabled
+        maxChargeLimit
+        minChargeLimit
+    }
+}
+`
+
+const ChargeSettingsSchemaQuery = `
+query ChargeSettingsSchema {
+    __schema {
+        mutationType {
+            fields {
+                name
+                args {
+                    name
+                    type {
+                        kind
+                        name
+                        ofType {
+                            kind
+                            name
+                        }
+                    }
+                }
+            }
+        }
+    }
+    __type(name: "EnodeChargeSettingsInput") {
+        inputFields {
+            name
+            type {
+                kind
+                name
+                ofType {
+                    kind
+                    name
+                }
+            }
+        }
+    }
+}
+`
+
 const SmartBatteriesQuery = `
 query SmartBatteries {
     smartBatteries {
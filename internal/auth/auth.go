@@ -2,9 +2,11 @@ package auth
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/pietern/frankie/internal/api"
+	"github.com/pietern/frankie/internal/config"
 	"github.com/pietern/frankie/internal/models"
 )
 
@@ -38,6 +40,34 @@ func (m *Manager) Login(email, password string) error {
 		ExpiresAt:    expiresAt,
 	}
 
+	lock, err := lockCredentials(config.GetProfile())
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	if err := SaveCredentials(creds); err != nil {
+		return fmt.Errorf("failed to save credentials: %w", err)
+	}
+
+	return nil
+}
+
+// LoginWithRefreshToken logs in by renewing a refresh token that was issued
+// elsewhere, so no password is needed. The auth token it was issued with may
+// be empty.
+func (m *Manager) LoginWithRefreshToken(refreshToken, authToken string) error {
+	creds, err := m.renew(authToken, refreshToken)
+	if err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+
+	lock, err := lockCredentials(config.GetProfile())
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
 	if err := SaveCredentials(creds); err != nil {
 		return fmt.Errorf("failed to save credentials: %w", err)
 	}
@@ -62,7 +92,7 @@ func (m *Manager) GetValidToken() (string, error) {
 
 	// Check if token needs refresh
 	if IsTokenExpired(creds.AuthToken, TokenRefreshMargin) {
-		newCreds, err := m.RefreshToken(creds)
+		newCreds, err := m.refreshLocked()
 		if err != nil {
 			return "", fmt.Errorf("token refresh failed: %w", err)
 		}
@@ -72,19 +102,42 @@ func (m *Manager) GetValidToken() (string, error) {
 	return creds.AuthToken, nil
 }
 
-// RefreshToken renews the auth token using the refresh token
-func (m *Manager) RefreshToken(creds *models.Credentials) (*models.Credentials, error) {
-	newAuthToken, newRefreshToken, err := m.client.RenewToken(creds.AuthToken, creds.RefreshToken)
+// refreshLocked renews the auth token while holding the credentials lock.
+// The credentials are re-read first: if another process renewed them while
+// we waited, its token is used, as the refresh token we read may have been
+// rotated and renewing with it again would log us out.
+func (m *Manager) refreshLocked() (*models.Credentials, error) {
+	profile := config.GetProfile()
+	lock, err := lockCredentials(profile)
 	if err != nil {
 		return nil, err
 	}
+	defer lock.Unlock()
 
-	expiresAt, _ := ParseJWTExpiration(newAuthToken)
+	store, err := Store()
+	if err != nil {
+		return nil, err
+	}
+	creds, err := store.Load(profile)
+	if err != nil {
+		return nil, err
+	}
+	if creds == nil {
+		return nil, fmt.Errorf("not logged in")
+	}
+	if !IsTokenExpired(creds.AuthToken, TokenRefreshMargin) {
+		return creds, nil
+	}
 
-	newCreds := &models.Credentials{
-		AuthToken:    newAuthToken,
-		RefreshToken: newRefreshToken,
-		ExpiresAt:    expiresAt,
+	return m.RefreshToken(creds)
+}
+
+// RefreshToken renews the auth token using the refresh token. Callers must
+// hold the credentials lock.
+func (m *Manager) RefreshToken(creds *models.Credentials) (*models.Credentials, error) {
+	newCreds, err := m.renew(creds.AuthToken, creds.RefreshToken)
+	if err != nil {
+		return nil, err
 	}
 
 	if err := SaveCredentials(newCreds); err != nil {
@@ -94,6 +147,25 @@ func (m *Manager) RefreshToken(creds *models.Credentials) (*models.Credentials,
 	return newCreds, nil
 }
 
+// renew exchanges a refresh token for new credentials
+func (m *Manager) renew(authToken, refreshToken string) (*models.Credentials, error) {
+	newAuthToken, newRefreshToken, err := m.client.RenewToken(authToken, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if newAuthToken == "" {
+		return nil, fmt.Errorf("no token received")
+	}
+
+	expiresAt, _ := ParseJWTExpiration(newAuthToken)
+
+	return &models.Credentials{
+		AuthToken:    newAuthToken,
+		RefreshToken: newRefreshToken,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
 // EnsureAuthenticated loads credentials and sets up the client
 func (m *Manager) EnsureAuthenticated() error {
 	token, err := m.GetValidToken()
@@ -104,6 +176,26 @@ func (m *Manager) EnsureAuthenticated() error {
 	return nil
 }
 
+// TokenSource returns a function for api.Client.SetTokenSource that provides
+// a valid token for every request, renewing it before it expires. It returns
+// an empty token when not logged in, so public data can still be fetched.
+func (m *Manager) TokenSource() func() (string, error) {
+	// Renewals go through their own client, as the token source is called
+	// while the shared client is building a request
+	renewer := NewManager(api.NewClient())
+
+	var mu sync.Mutex
+	return func() (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !CredentialsExist() {
+			return "", nil
+		}
+		return renewer.GetValidToken()
+	}
+}
+
 // IsLoggedIn checks if user has valid credentials
 func IsLoggedIn() bool {
 	creds, err := LoadCredentials()
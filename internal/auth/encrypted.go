@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pietern/frankie/internal/config"
+	"github.com/pietern/frankie/internal/models"
+)
+
+const (
+	// PassphraseEnv is the environment variable holding the passphrase of encrypted credentials
+	PassphraseEnv = "FRANKIE_CREDENTIAL_PASSPHRASE"
+
+	encryptedFileVersion = 1
+	kdfIterations        = 600000
+	saltSize             = 16
+)
+
+// PromptPassphrase asks for the passphrase of encrypted credentials when
+// FRANKIE_CREDENTIAL_PASSPHRASE is not set. It is nil when there is no terminal.
+var PromptPassphrase func() (string, error)
+
+// encryptedFile is the on-disk format of encrypted credentials
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// encryptedFileStore keeps credentials in a file encrypted with AES-256-GCM,
+// using a key derived from a passphrase with PBKDF2-SHA256
+type encryptedFileStore struct {
+	mu         sync.Mutex
+	passphrase string
+	// salt and key are those of the last file read or written, so the key
+	// is only derived once per process
+	salt []byte
+	key  []byte
+}
+
+func encryptedCredentialsPath(profile string) string {
+	return filepath.Join(config.GetProfileDir(profile), "credentials.enc")
+}
+
+func (s *encryptedFileStore) Load(profile string) (*models.Credentials, error) {
+	data, err := os.ReadFile(encryptedCredentialsPath(profile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read credentials: %w", err)
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials: %w", err)
+	}
+	if file.Version != encryptedFileVersion {
+		return nil, fmt.Errorf("unsupported encrypted credentials version: %d", file.Version)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, err := s.deriveKey(file.Salt, file.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Ciphertext, []byte(profile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt credentials (wrong passphrase?)")
+	}
+
+	var creds models.Credentials
+	if err := json.Unmarshal(plaintext, &creds); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials: %w", err)
+	}
+	return &creds, nil
+}
+
+func (s *encryptedFileStore) Save(profile string, creds *models.Credentials) error {
+	plaintext, err := json.Marshal(creds)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	salt := s.salt
+	if salt == nil {
+		salt = make([]byte, saltSize)
+		rand.Read(salt)
+	}
+	key, err := s.deriveKey(salt, kdfIterations)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	rand.Read(nonce)
+	file := encryptedFile{
+		Version:    encryptedFileVersion,
+		KDF:        "pbkdf2-sha256",
+		Iterations: kdfIterations,
+		Salt:       salt,
+		Nonce:      nonce,
+		// The profile is authenticated, so files cannot be swapped between profiles
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, []byte(profile)),
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+
+	if err := os.MkdirAll(config.GetProfileDir(profile), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := writeFileAtomic(encryptedCredentialsPath(profile), data); err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	return nil
+}
+
+func (s *encryptedFileStore) Delete(profile string) error {
+	if err := os.Remove(encryptedCredentialsPath(profile)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete credentials: %w", err)
+	}
+	return nil
+}
+
+func (s *encryptedFileStore) Exists(profile string) bool {
+	_, err := os.Stat(encryptedCredentialsPath(profile))
+	return err == nil
+}
+
+func (s *encryptedFileStore) Location(profile string) string {
+	return encryptedCredentialsPath(profile) + " (encrypted)"
+}
+
+// deriveKey returns the key for a salt, asking for the passphrase if needed.
+// The caller must hold s.mu.
+func (s *encryptedFileStore) deriveKey(salt []byte, iterations int) ([]byte, error) {
+	if s.key != nil && string(s.salt) == string(salt) {
+		return s.key, nil
+	}
+
+	if s.passphrase == "" {
+		s.passphrase = os.Getenv(PassphraseEnv)
+	}
+	if s.passphrase == "" {
+		if PromptPassphrase == nil {
+			return nil, fmt.Errorf("encrypted credentials require a passphrase (set %s)", PassphraseEnv)
+		}
+		passphrase, err := PromptPassphrase()
+		if err != nil {
+			return nil, err
+		}
+		if passphrase == "" {
+			return nil, fmt.Errorf("passphrase is required")
+		}
+		s.passphrase = passphrase
+	}
+
+	key, err := pbkdf2.Key(sha256.New, s.passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	s.salt, s.key = salt, key
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pietern/frankie/internal/config"
+	"github.com/pietern/frankie/internal/models"
+)
+
+func TestEncryptedFileStore(t *testing.T) {
+	creds := &models.Credentials{
+		AuthToken:    "auth-token",
+		RefreshToken: "refresh-token",
+		ExpiresAt:    time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name string
+		// saveProfile and loadProfile are the profiles the credentials are
+		// saved and loaded with; the file is copied between them if they differ
+		saveProfile    string
+		loadProfile    string
+		savePassphrase string
+		loadPassphrase string
+		wantErr        string
+	}{
+		{
+			name:           "round trip",
+			saveProfile:    config.DefaultProfile,
+			loadProfile:    config.DefaultProfile,
+			savePassphrase: "correct horse",
+			loadPassphrase: "correct horse",
+		},
+		{
+			name:           "round trip of another profile",
+			saveProfile:    "work",
+			loadProfile:    "work",
+			savePassphrase: "correct horse",
+			loadPassphrase: "correct horse",
+		},
+		{
+			name:           "wrong passphrase",
+			saveProfile:    config.DefaultProfile,
+			loadProfile:    config.DefaultProfile,
+			savePassphrase: "correct horse",
+			loadPassphrase: "battery staple",
+			wantErr:        "wrong passphrase",
+		},
+		{
+			name:           "swapped between profiles",
+			saveProfile:    "work",
+			loadProfile:    config.DefaultProfile,
+			savePassphrase: "correct horse",
+			loadPassphrase: "correct horse",
+			wantErr:        "wrong passphrase",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(config.HomeEnv, t.TempDir())
+			t.Setenv(PassphraseEnv, "")
+
+			saver := &encryptedFileStore{passphrase: tt.savePassphrase}
+			if err := saver.Save(tt.saveProfile, creds); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+
+			data, err := os.ReadFile(encryptedCredentialsPath(tt.saveProfile))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(data), creds.RefreshToken) {
+				t.Fatal("credentials are stored in plain text")
+			}
+			if tt.loadProfile != tt.saveProfile {
+				path := encryptedCredentialsPath(tt.loadProfile)
+				if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, data, 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// A new store derives the key again, as another process would
+			loader := &encryptedFileStore{passphrase: tt.loadPassphrase}
+			got, err := loader.Load(tt.loadProfile)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if got == nil || *got != *creds {
+				t.Fatalf("Load = %+v, want %+v", got, creds)
+			}
+		})
+	}
+}
+
+func TestEncryptedFileStoreMissing(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+
+	s := &encryptedFileStore{passphrase: "correct horse"}
+	creds, err := s.Load(config.DefaultProfile)
+	if err != nil || creds != nil {
+		t.Fatalf("Load = %v, %v, want no credentials", creds, err)
+	}
+	if s.Exists(config.DefaultProfile) {
+		t.Fatal("Exists = true without credentials")
+	}
+}
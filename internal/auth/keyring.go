@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+
+	"github.com/pietern/frankie/internal/config"
+	"github.com/pietern/frankie/internal/models"
+)
+
+// keyringStore keeps credentials in the system keyring: the Secret Service
+// over D-Bus on Linux, the Keychain on macOS and the Credential Manager on
+// Windows. Each profile is an account of the "frankie" service.
+type keyringStore struct{}
+
+func (keyringStore) Load(profile string) (*models.Credentials, error) {
+	secret, err := keyring.Get(config.AppName, profile)
+	if err != nil {
+		if errors.Is(err, keyring.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read credentials from keyring: %w", err)
+	}
+
+	var creds models.Credentials
+	if err := json.Unmarshal([]byte(secret), &creds); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials: %w", err)
+	}
+	return &creds, nil
+}
+
+func (keyringStore) Save(profile string, creds *models.Credentials) error {
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+	if err := keyring.Set(config.AppName, profile, string(data)); err != nil {
+		return fmt.Errorf("failed to write credentials to keyring: %w", err)
+	}
+	return nil
+}
+
+func (keyringStore) Delete(profile string) error {
+	if err := keyring.Delete(config.AppName, profile); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("failed to delete credentials from keyring: %w", err)
+	}
+	return nil
+}
+
+func (s keyringStore) Exists(profile string) bool {
+	creds, err := s.Load(profile)
+	return err == nil && creds != nil
+}
+
+func (keyringStore) Location(profile string) string {
+	return fmt.Sprintf("system keyring (service %s, account %s)", config.AppName, profile)
+}
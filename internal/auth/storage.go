@@ -4,14 +4,224 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/pietern/frankie/internal/config"
+	"github.com/pietern/frankie/internal/filelock"
 	"github.com/pietern/frankie/internal/models"
 )
 
-// LoadCredentials reads stored credentials from disk
+// lockTimeout is how long to wait for another process that is refreshing or saving credentials
+const lockTimeout = 30 * time.Second
+
+// Credential store backends
+const (
+	StoreFile          = "file"
+	StoreKeyring       = "keyring"
+	StoreEncryptedFile = "encrypted-file"
+)
+
+// CredentialStore keeps the credentials of each profile
+type CredentialStore interface {
+	// Load returns the credentials of a profile, or nil if there are none
+	Load(profile string) (*models.Credentials, error)
+	Save(profile string, creds *models.Credentials) error
+	// Delete removes the credentials of a profile; it is not an error if there are none
+	Delete(profile string) error
+	// Exists reports whether a profile has credentials, without decrypting them
+	Exists(profile string) bool
+	// Location describes where the credentials of a profile are kept
+	Location(profile string) string
+}
+
+var (
+	storeMu      sync.Mutex
+	storeName    string
+	currentStore CredentialStore
+)
+
+// Store returns the configured credential store
+func Store() (CredentialStore, error) {
+	name, err := config.GetCredentialStore()
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = StoreFile
+	}
+
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	if currentStore != nil && storeName == name {
+		return currentStore, nil
+	}
+
+	var store CredentialStore
+	switch name {
+	case StoreFile:
+		store = fileStore{}
+	case StoreKeyring:
+		store = keyringStore{}
+	case StoreEncryptedFile:
+		store = &encryptedFileStore{}
+	default:
+		return nil, fmt.Errorf("unknown credential store: %s (must be %s, %s or %s)", name, StoreFile, StoreKeyring, StoreEncryptedFile)
+	}
+
+	storeName, currentStore = name, store
+	return store, nil
+}
+
+// LoadCredentials reads the stored credentials of the active profile
 func LoadCredentials() (*models.Credentials, error) {
-	path := config.GetCredentialsPath()
+	return LoadProfileCredentials(config.GetProfile())
+}
+
+// LoadProfileCredentials reads the stored credentials of a profile. Credentials
+// in the plaintext file are moved to the configured store if it has none.
+func LoadProfileCredentials(profile string) (*models.Credentials, error) {
+	store, err := Store()
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := store.Load(profile)
+	if err != nil || creds != nil {
+		return creds, err
+	}
+	if _, ok := store.(fileStore); ok {
+		return nil, nil
+	}
+	return migrateCredentials(store, profile)
+}
+
+// migrateCredentials moves credentials from the plaintext file to another store
+func migrateCredentials(store CredentialStore, profile string) (*models.Credentials, error) {
+	if !(fileStore{}).Exists(profile) {
+		return nil, nil
+	}
+
+	lock, err := lockCredentials(profile)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	// Another process may have migrated the credentials while we waited
+	if creds, err := store.Load(profile); err != nil || creds != nil {
+		return creds, err
+	}
+	creds, err := fileStore{}.Load(profile)
+	if err != nil || creds == nil {
+		return nil, err
+	}
+
+	if err := store.Save(profile, creds); err != nil {
+		return nil, fmt.Errorf("failed to migrate credentials: %w", err)
+	}
+	if err := (fileStore{}).Delete(profile); err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(os.Stderr, "Moved credentials from %s to %s\n", fileStore{}.Location(profile), store.Location(profile))
+	return creds, nil
+}
+
+// SaveCredentials stores the credentials of the active profile. Callers must
+// hold the credentials lock.
+func SaveCredentials(creds *models.Credentials) error {
+	store, err := Store()
+	if err != nil {
+		return err
+	}
+	return store.Save(config.GetProfile(), creds)
+}
+
+// DeleteCredentials removes the stored credentials of the active profile
+func DeleteCredentials() error {
+	return DeleteProfileCredentials(config.GetProfile())
+}
+
+// DeleteProfileCredentials removes the stored credentials of a profile from
+// the configured store and the plaintext file
+func DeleteProfileCredentials(profile string) error {
+	store, err := Store()
+	if err != nil {
+		return err
+	}
+
+	lock, err := lockCredentials(profile)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	if err := store.Delete(profile); err != nil {
+		return err
+	}
+	return fileStore{}.Delete(profile)
+}
+
+// CredentialsExist checks if the active profile has stored credentials
+func CredentialsExist() bool {
+	profile := config.GetProfile()
+	if (fileStore{}).Exists(profile) {
+		return true
+	}
+	store, err := Store()
+	return err == nil && store.Exists(profile)
+}
+
+// CredentialsLocation describes where the credentials of the active profile are kept
+func CredentialsLocation() string {
+	store, err := Store()
+	if err != nil {
+		return err.Error()
+	}
+	return store.Location(config.GetProfile())
+}
+
+// lockCredentials takes the inter-process lock on the credentials of a
+// profile. Credentials must be re-read after taking the lock, since another
+// process may have changed them.
+func lockCredentials(profile string) (*filelock.Lock, error) {
+	return filelock.Acquire(filepath.Join(config.GetProfileDir(profile), "credentials.lock"), lockTimeout)
+}
+
+// writeFileAtomic replaces a file with data, readable only by the owner.
+// Readers see either the old or the new contents, never a partial write.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// fileStore keeps credentials as plaintext JSON, readable only by the owner
+type fileStore struct{}
+
+func (fileStore) Load(profile string) (*models.Credentials, error) {
+	path := config.GetProfileCredentialsPath(profile)
 
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -29,9 +239,8 @@ func LoadCredentials() (*models.Credentials, error) {
 	return &creds, nil
 }
 
-// SaveCredentials writes credentials to disk with secure permissions
-func SaveCredentials(creds *models.Credentials) error {
-	if err := config.EnsureConfigDir(); err != nil {
+func (fileStore) Save(profile string, creds *models.Credentials) error {
+	if err := os.MkdirAll(config.GetProfileDir(profile), 0700); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
@@ -40,19 +249,18 @@ func SaveCredentials(creds *models.Credentials) error {
 		return fmt.Errorf("failed to marshal credentials: %w", err)
 	}
 
-	path := config.GetCredentialsPath()
+	path := config.GetProfileCredentialsPath(profile)
 
 	// Write with secure permissions (0600 = owner read/write only)
-	if err := os.WriteFile(path, data, 0600); err != nil {
+	if err := writeFileAtomic(path, data); err != nil {
 		return fmt.Errorf("failed to write credentials: %w", err)
 	}
 
 	return nil
 }
 
-// DeleteCredentials removes stored credentials
-func DeleteCredentials() error {
-	path := config.GetCredentialsPath()
+func (fileStore) Delete(profile string) error {
+	path := config.GetProfileCredentialsPath(profile)
 
 	if err := os.Remove(path); err != nil {
 		if os.IsNotExist(err) {
@@ -64,9 +272,11 @@ func DeleteCredentials() error {
 	return nil
 }
 
-// CredentialsExist checks if credentials file exists
-func CredentialsExist() bool {
-	path := config.GetCredentialsPath()
-	_, err := os.Stat(path)
+func (fileStore) Exists(profile string) bool {
+	_, err := os.Stat(config.GetProfileCredentialsPath(profile))
 	return err == nil
 }
+
+func (fileStore) Location(profile string) string {
+	return config.GetProfileCredentialsPath(profile)
+}
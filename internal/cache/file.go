@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// File is a cache that keeps each entry in a file in a directory, so it is
+// shared between runs. It is safe for concurrent use, also across processes.
+type File struct {
+	dir string
+}
+
+// NewFile creates a cache that keeps its entries in dir. The directory is
+// created when the first entry is stored.
+func NewFile(dir string) *File {
+	return &File{dir: dir}
+}
+
+// path returns the file of the entry stored under key
+func (c *File) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+// Get returns the value stored under key, if it has not expired
+func (c *File) Get(key string) ([]byte, bool) {
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	// An entry is its expiry time in Unix nanoseconds, a newline and the value
+	header, value, ok := bytes.Cut(data, []byte("\n"))
+	if !ok {
+		return nil, false
+	}
+	expires, err := strconv.ParseInt(string(header), 10, 64)
+	if err != nil || time.Now().UnixNano() > expires {
+		os.Remove(path)
+		return nil, false
+	}
+	return value, true
+}
+
+// Set stores a value under key for the given duration. Errors are ignored,
+// as the value can always be fetched again.
+func (c *File) Set(key string, value []byte, ttl time.Duration) {
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return
+	}
+
+	// Write to a temporary file first, so readers never see a partial entry
+	f, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	header := strconv.FormatInt(time.Now().Add(ttl).UnixNano(), 10) + "\n"
+	_, err = f.WriteString(header)
+	if err == nil {
+		_, err = f.Write(value)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), c.path(key))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+}
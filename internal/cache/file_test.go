@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFile(t *testing.T) {
+	tests := []struct {
+		name string
+		// prepare stores entries, or writes files directly, before the lookup
+		prepare   func(t *testing.T, c *File)
+		key       string
+		want      string
+		wantFound bool
+		// wantRemoved reports whether the entry's file is removed by the lookup
+		wantRemoved bool
+	}{
+		{
+			name:      "missing",
+			prepare:   func(t *testing.T, c *File) {},
+			key:       "prices",
+			wantFound: false,
+		},
+		{
+			name: "stored",
+			prepare: func(t *testing.T, c *File) {
+				c.Set("prices", []byte("{\"a\":1}\nsecond line"), time.Minute)
+			},
+			key:       "prices",
+			want:      "{\"a\":1}\nsecond line",
+			wantFound: true,
+		},
+		{
+			name: "empty value",
+			prepare: func(t *testing.T, c *File) {
+				c.Set("prices", nil, time.Minute)
+			},
+			key:       "prices",
+			want:      "",
+			wantFound: true,
+		},
+		{
+			name: "overwritten",
+			prepare: func(t *testing.T, c *File) {
+				c.Set("prices", []byte("old"), time.Minute)
+				c.Set("prices", []byte("new"), time.Minute)
+			},
+			key:       "prices",
+			want:      "new",
+			wantFound: true,
+		},
+		{
+			name: "other key",
+			prepare: func(t *testing.T, c *File) {
+				c.Set("prices", []byte("value"), time.Minute)
+			},
+			key:       "usage",
+			wantFound: false,
+		},
+		{
+			name: "expired",
+			prepare: func(t *testing.T, c *File) {
+				c.Set("prices", []byte("value"), -time.Second)
+			},
+			key:         "prices",
+			wantFound:   false,
+			wantRemoved: true,
+		},
+		{
+			name: "corrupt header",
+			prepare: func(t *testing.T, c *File) {
+				writeEntry(t, c, "prices", "soon\nvalue")
+			},
+			key:         "prices",
+			wantFound:   false,
+			wantRemoved: true,
+		},
+		{
+			name: "truncated",
+			prepare: func(t *testing.T, c *File) {
+				writeEntry(t, c, "prices", "1234")
+			},
+			key:       "prices",
+			wantFound: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The directory is created when the first entry is stored
+			c := NewFile(filepath.Join(t.TempDir(), "cache"))
+			tt.prepare(t, c)
+
+			got, found := c.Get(tt.key)
+			if found != tt.wantFound || string(got) != tt.want {
+				t.Errorf("Get(%q) = %q, %v, want %q, %v", tt.key, got, found, tt.want, tt.wantFound)
+			}
+			if _, err := os.Stat(c.path(tt.key)); tt.wantRemoved && err == nil {
+				t.Error("expired entry was not removed")
+			}
+
+			// No temporary files are left behind
+			entries, _ := os.ReadDir(c.dir)
+			for _, e := range entries {
+				if strings.HasPrefix(e.Name(), ".tmp-") {
+					t.Errorf("temporary file %s left behind", e.Name())
+				}
+			}
+		})
+	}
+}
+
+func TestFileConcurrent(t *testing.T) {
+	c := NewFile(t.TempDir())
+	values := []string{"first", "second", "third"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Go(func() {
+			c.Set("prices", []byte(values[i%len(values)]), time.Minute)
+			if got, ok := c.Get("prices"); ok && !slices.Contains(values, string(got)) {
+				t.Errorf("read partial entry %q", got)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// writeEntry writes the raw file of an entry
+func writeEntry(t *testing.T, c *File, key, data string) {
+	t.Helper()
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(c.path(key), []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
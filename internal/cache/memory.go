@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type entry struct {
+	value   []byte
+	expires time.Time
+}
+
+// Memory is an in-memory cache with per-entry expiry. It is safe for concurrent use.
+type Memory struct {
+	mu        sync.Mutex
+	entries   map[string]entry
+	lastSweep time.Time
+}
+
+// NewMemory creates an empty in-memory cache
+func NewMemory() *Memory {
+	return &Memory{
+		entries:   make(map[string]entry),
+		lastSweep: time.Now(),
+	}
+}
+
+// Get returns the value stored under key, if it has not expired
+func (c *Memory) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || time.Now().After(e.expires) {
+		return nil, false
+	}
+	return e.value, true
+}
+
+// Set stores a value under key for the given duration
+func (c *Memory) Set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.entries[key] = entry{value: value, expires: now.Add(ttl)}
+
+	// Drop expired entries now and then, so the cache doesn't grow unbounded
+	if now.Sub(c.lastSweep) > ttl {
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.lastSweep = now
+	}
+}
@@ -1,26 +1,110 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 )
 
 const (
 	AppName = "frankie"
+
+	// HomeEnv is the environment variable that keeps all files in a single
+	// directory, with config, data, cache and state subdirectories
+	HomeEnv = "FRANKIE_HOME"
 )
 
-// GetConfigDir returns the configuration directory path
-func GetConfigDir() string {
+// Base directories, following the XDG base directory specification
+var (
+	configBase = baseDir{name: "config", env: "XDG_CONFIG_HOME", fallback: ".config"}
+	dataBase   = baseDir{name: "data", env: "XDG_DATA_HOME", fallback: filepath.Join(".local", "share")}
+	cacheBase  = baseDir{name: "cache", env: "XDG_CACHE_HOME", fallback: ".cache"}
+	stateBase  = baseDir{name: "state", env: "XDG_STATE_HOME", fallback: filepath.Join(".local", "state")}
+)
+
+// baseDir locates one kind of files: in $FRANKIE_HOME/<name>, in the
+// directory named by the XDG environment variable, or in its default under
+// the home directory
+type baseDir struct {
+	name     string
+	env      string
+	fallback string
+}
+
+func (b baseDir) path() (string, error) {
+	if home := os.Getenv(HomeEnv); home != "" {
+		abs, err := filepath.Abs(home)
+		if err != nil {
+			return "", fmt.Errorf("invalid $%s: %w", HomeEnv, err)
+		}
+		return filepath.Join(abs, b.name), nil
+	}
+	// Relative paths are invalid in XDG variables and are ignored
+	if dir := os.Getenv(b.env); filepath.IsAbs(dir) {
+		return filepath.Join(dir, AppName), nil
+	}
 	home, err := os.UserHomeDir()
-	if err != nil {
-		return ".config/frankie"
+	if err != nil || home == "" {
+		return "", fmt.Errorf("cannot determine the home directory (set $%s or $%s)", HomeEnv, b.env)
 	}
-	return filepath.Join(home, ".config", AppName)
+	return filepath.Join(home, b.fallback, AppName), nil
+}
+
+// dir returns the path, or "" if it cannot be determined; CheckDirs reports why
+func (b baseDir) dir() string {
+	dir, _ := b.path()
+	return dir
+}
+
+// CheckDirs verifies that all directories can be determined, so the getters
+// below never return a relative path
+func CheckDirs() error {
+	for _, b := range []baseDir{configBase, dataBase, cacheBase, stateBase} {
+		if _, err := b.path(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetConfigDir returns the directory of settings and credentials
+func GetConfigDir() string {
+	return configBase.dir()
+}
+
+// GetDataDir returns the directory of the history databases
+func GetDataDir() string {
+	return dataBase.dir()
+}
+
+// GetCacheDir returns the directory of cached API responses
+func GetCacheDir() string {
+	return cacheBase.dir()
+}
+
+// GetStateDir returns the directory of logs
+func GetStateDir() string {
+	return stateBase.dir()
 }
 
-// GetCredentialsPath returns the path to the credentials file
+// GetCredentialsPath returns the path to the credentials file of the active profile
 func GetCredentialsPath() string {
-	return filepath.Join(GetConfigDir(), "credentials.json")
+	return GetProfileCredentialsPath(GetProfile())
+}
+
+// GetProfileCredentialsPath returns the path to the credentials file of a profile
+func GetProfileCredentialsPath(profile string) string {
+	return filepath.Join(GetProfileDir(profile), "credentials.json")
+}
+
+// GetDatabasePath returns the path to the local history database of the active profile
+func GetDatabasePath() string {
+	return filepath.Join(GetProfileDataDir(GetProfile()), "history.db")
+}
+
+// GetDaemonConfigPath returns the path to the daemon configuration file of the active profile
+func GetDaemonConfigPath() string {
+	return filepath.Join(GetProfileDir(GetProfile()), "daemon.yaml")
 }
 
 // EnsureConfigDir creates the config directory if it doesn't exist
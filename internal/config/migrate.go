@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pietern/frankie/internal/filelock"
+)
+
+// migrateLockTimeout is how long MigrateConfigDir waits for another process
+// moving the same files
+const migrateLockTimeout = 10 * time.Second
+
+// legacyConfigDir returns ~/.config/frankie, where settings and credentials
+// were kept before $XDG_CONFIG_HOME and $FRANKIE_HOME were followed, or "" if
+// that is still the config directory
+func legacyConfigDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return ""
+	}
+	dir := filepath.Join(home, ".config", AppName)
+	if dir == GetConfigDir() {
+		return ""
+	}
+	return dir
+}
+
+// GetLegacyDatabasePaths returns where the history database of a profile may
+// have been kept by older versions: in the config directory, before the data
+// directory existed, and in ~/.config/frankie if another config directory
+// is used now
+func GetLegacyDatabasePaths(profile string) []string {
+	paths := []string{filepath.Join(GetProfileDir(profile), "history.db")}
+	if legacy := legacyConfigDir(); legacy != "" {
+		paths = append(paths, filepath.Join(profileDir(legacy, profile), "history.db"))
+	}
+	return paths
+}
+
+// MigrateConfigDir moves settings and credentials from ~/.config/frankie to
+// the config directory, if another one is used and it does not exist yet. It
+// returns the old directory if files were moved. History databases are left
+// in place; they are moved separately when the history is opened.
+//
+// If both directories exist and only the old one has settings or
+// credentials, an error says so, as they would otherwise be ignored silently.
+func MigrateConfigDir() (string, error) {
+	oldDir := legacyConfigDir()
+	if oldDir == "" || !hasConfigFiles(oldDir) {
+		return "", nil
+	}
+	newDir := GetConfigDir()
+	if _, err := os.Stat(newDir); err == nil {
+		if hasConfigFiles(newDir) {
+			return "", nil
+		}
+		return "", fmt.Errorf("settings and credentials in %s are not used, as the config directory is %s; move them there to use them", oldDir, newDir)
+	}
+
+	lock, err := filelock.Acquire(filepath.Join(oldDir, "migrate.lock"), migrateLockTimeout)
+	if err != nil {
+		return "", err
+	}
+	defer lock.Unlock()
+
+	// Another process may have moved the files while waiting for the lock
+	if _, err := os.Stat(newDir); err == nil {
+		return "", nil
+	}
+
+	err = filepath.WalkDir(oldDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || isMigrationExempt(d.Name()) {
+			return err
+		}
+		rel, err := filepath.Rel(oldDir, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(newDir, rel)
+		if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+			return err
+		}
+		return os.Rename(path, target)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to move settings and credentials from %s to %s: %w", oldDir, newDir, err)
+	}
+	return oldDir, nil
+}
+
+// hasConfigFiles reports whether a config directory has settings or
+// credentials of the default profile, or any other profile
+func hasConfigFiles(dir string) bool {
+	for _, name := range []string{"config.yaml", "credentials.json", "credentials.enc", "profiles"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isMigrationExempt reports whether a file stays in the old config directory:
+// history databases, which are moved when opened, and lock files
+func isMigrationExempt(name string) bool {
+	return strings.HasPrefix(name, "history.db") || strings.HasSuffix(name, ".lock")
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultProfile is the profile whose files live directly in the base directories
+	DefaultProfile = "default"
+
+	// ProfileEnv is the environment variable that selects a profile
+	ProfileEnv = "FRANKIE_PROFILE"
+)
+
+// Sources of the active profile, in order of precedence
+const (
+	ProfileSourceFlag    = "flag"
+	ProfileSourceEnv     = "env"
+	ProfileSourceCurrent = "current"
+	ProfileSourceDefault = "default"
+)
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// profileFlag is the profile selected with --profile
+var profileFlag string
+
+// SetProfile selects the profile for this process, taking precedence over
+// FRANKIE_PROFILE and the current profile
+func SetProfile(name string) {
+	profileFlag = name
+}
+
+// GetProfile returns the name of the active profile
+func GetProfile() string {
+	name, _ := GetProfileWithSource()
+	return name
+}
+
+// GetProfileWithSource returns the name of the active profile and where it
+// was selected: by --profile, FRANKIE_PROFILE, 'profiles use', or by default
+func GetProfileWithSource() (string, string) {
+	if profileFlag != "" {
+		return profileFlag, ProfileSourceFlag
+	}
+	if env := os.Getenv(ProfileEnv); env != "" {
+		return env, ProfileSourceEnv
+	}
+	if current := GetCurrentProfile(); current != "" {
+		return current, ProfileSourceCurrent
+	}
+	return DefaultProfile, ProfileSourceDefault
+}
+
+// ValidateProfileName checks that a profile name is safe to use as a directory name
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name '%s' (use letters, digits, '.', '_' and '-')", name)
+	}
+	return nil
+}
+
+// GetProfileDir returns the directory holding the settings and credentials of a profile
+func GetProfileDir(name string) string {
+	return profileDir(GetConfigDir(), name)
+}
+
+// GetProfileDataDir returns the directory holding the history database of a profile
+func GetProfileDataDir(name string) string {
+	return profileDir(GetDataDir(), name)
+}
+
+// GetProfileCacheDir returns the directory holding cached API responses of a profile
+func GetProfileCacheDir(name string) string {
+	return profileDir(GetCacheDir(), name)
+}
+
+// GetProfileStateDir returns the directory holding the logs of a profile
+func GetProfileStateDir(name string) string {
+	return profileDir(GetStateDir(), name)
+}
+
+// profileDir returns the directory of a profile within a base directory
+func profileDir(base, name string) string {
+	if name == DefaultProfile {
+		return base
+	}
+	return filepath.Join(base, "profiles", name)
+}
+
+// ProfileExists reports whether a profile has been created
+func ProfileExists(name string) bool {
+	if name == DefaultProfile {
+		return true
+	}
+	_, err := os.Stat(GetProfileDir(name))
+	return err == nil
+}
+
+// ListProfiles returns the default profile followed by all other profiles, sorted
+func ListProfiles() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(GetConfigDir(), "profiles"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && e.Name() != DefaultProfile && ValidateProfileName(e.Name()) == nil {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...), nil
+}
+
+// RemoveProfile deletes a profile and all of its files. The default profile
+// cannot be removed.
+func RemoveProfile(name string) error {
+	if name == DefaultProfile {
+		return fmt.Errorf("the default profile cannot be removed")
+	}
+	if !ProfileExists(name) {
+		return fmt.Errorf("profile '%s' does not exist", name)
+	}
+	for _, dir := range []string{GetProfileDir(name), GetProfileDataDir(name), GetProfileCacheDir(name), GetProfileStateDir(name)} {
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("failed to remove profile: %w", err)
+		}
+	}
+	if GetCurrentProfile() == name {
+		return SetCurrentProfile(DefaultProfile)
+	}
+	return nil
+}
+
+func getCurrentProfilePath() string {
+	return filepath.Join(GetConfigDir(), "current-profile")
+}
+
+// GetCurrentProfile returns the profile selected with 'profiles use', or ""
+func GetCurrentProfile() string {
+	data, err := os.ReadFile(getCurrentProfilePath())
+	if err != nil {
+		return ""
+	}
+	name := strings.TrimSpace(string(data))
+	if ValidateProfileName(name) != nil {
+		return ""
+	}
+	return name
+}
+
+// SetCurrentProfile persists the profile to use when none is selected
+func SetCurrentProfile(name string) error {
+	if name == DefaultProfile {
+		if err := os.Remove(getCurrentProfilePath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to reset current profile: %w", err)
+		}
+		return nil
+	}
+
+	if err := EnsureConfigDir(); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(getCurrentProfilePath(), []byte(name+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to save current profile: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,365 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CredentialStoreEnv is the environment variable that overrides the credential store
+const CredentialStoreEnv = "FRANKIE_CREDENTIAL_STORE"
+
+// Setting keys in config.yaml
+const (
+	SettingCredentialStore = "credential_store"
+	SettingSite            = "site"
+	SettingCountry         = "country"
+	SettingResolution      = "resolution"
+	SettingTimezone        = "timezone"
+	SettingOutput          = "output"
+	SettingCacheTTL        = "cache_ttl"
+	SettingCompletionTTL   = "completion_cache_ttl"
+)
+
+// Sources of a setting value, in order of precedence after command-line flags
+const (
+	SettingSourceEnv     = "env"
+	SettingSourceProfile = "profile"
+	SettingSourceGlobal  = "global"
+	SettingSourceDefault = "default"
+)
+
+// Settings are the user settings in config.yaml
+type Settings struct {
+	// CredentialStore is where credentials are kept: file, keyring or encrypted-file
+	CredentialStore string `yaml:"credential_store,omitempty"`
+	// Site is the default site reference
+	Site string `yaml:"site,omitempty"`
+	// Country is the country of public prices: NL or BE
+	Country string `yaml:"country,omitempty"`
+	// Resolution is the price resolution in minutes: 15 or 60
+	Resolution string `yaml:"resolution,omitempty"`
+	// Timezone is the timezone used for dates and times, instead of the system timezone
+	Timezone string `yaml:"timezone,omitempty"`
+	// Output is the default output format
+	Output string `yaml:"output,omitempty"`
+	// CacheTTL is how long API responses are cached
+	CacheTTL string `yaml:"cache_ttl,omitempty"`
+	// CompletionTTL is how long values for shell completion are cached
+	CompletionTTL string `yaml:"completion_cache_ttl,omitempty"`
+}
+
+// SettingKey describes a setting
+type SettingKey struct {
+	Name        string
+	Env         string
+	Default     string
+	Description string
+
+	field    func(*Settings) *string
+	validate func(string) (string, error)
+}
+
+// SettingKeys lists all settings in the order they are shown
+var SettingKeys = []SettingKey{
+	{
+		Name:        SettingSite,
+		Env:         "FRANKIE_SITE",
+		Description: "default site reference",
+		field:       func(s *Settings) *string { return &s.Site },
+	},
+	{
+		Name:        SettingCountry,
+		Env:         "FRANKIE_COUNTRY",
+		Default:     "NL",
+		Description: "country of public prices (NL or BE)",
+		field:       func(s *Settings) *string { return &s.Country },
+		validate:    validateCountry,
+	},
+	{
+		Name:        SettingResolution,
+		Env:         "FRANKIE_RESOLUTION",
+		Default:     "60",
+		Description: "price resolution in minutes (15 or 60)",
+		field:       func(s *Settings) *string { return &s.Resolution },
+		validate:    validateResolution,
+	},
+	{
+		Name:        SettingTimezone,
+		Env:         "FRANKIE_TIMEZONE",
+		Description: "timezone for dates and times (default: system timezone)",
+		field:       func(s *Settings) *string { return &s.Timezone },
+		validate:    validateTimezone,
+	},
+	{
+		Name:        SettingOutput,
+		Env:         "FRANKIE_OUTPUT",
+		Default:     "table",
+		Description: "default output format",
+		field:       func(s *Settings) *string { return &s.Output },
+	},
+	{
+		Name:        SettingCacheTTL,
+		Env:         "FRANKIE_CACHE_TTL",
+		Default:     "5m",
+		Description: "how long API responses are cached by 'serve http'",
+		field:       func(s *Settings) *string { return &s.CacheTTL },
+		validate:    validateDuration,
+	},
+	{
+		Name:        SettingCompletionTTL,
+		Env:         "FRANKIE_COMPLETION_CACHE_TTL",
+		Default:     "1h",
+		Description: "how long sites and devices are cached for shell completion",
+		field:       func(s *Settings) *string { return &s.CompletionTTL },
+		validate:    validateDuration,
+	},
+	{
+		Name:        SettingCredentialStore,
+		Env:         CredentialStoreEnv,
+		Default:     "file",
+		Description: "where credentials are kept (file, keyring or encrypted-file)",
+		field:       func(s *Settings) *string { return &s.CredentialStore },
+		validate:    validateCredentialStore,
+	},
+}
+
+// SettingValue is the effective value of a setting and where it was set
+type SettingValue struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// LookupSettingKey returns the description of a setting
+func LookupSettingKey(name string) (SettingKey, error) {
+	for _, key := range SettingKeys {
+		if key.Name == name {
+			return key, nil
+		}
+	}
+	var names []string
+	for _, key := range SettingKeys {
+		names = append(names, key.Name)
+	}
+	return SettingKey{}, fmt.Errorf("unknown setting '%s' (must be one of %s)", name, strings.Join(names, ", "))
+}
+
+// Validate checks a value of the setting and returns it in canonical form
+func (k SettingKey) Validate(value string) (string, error) {
+	if k.validate == nil || value == "" {
+		return value, nil
+	}
+	return k.validate(value)
+}
+
+// GetSettingsPath returns the path to the global settings file
+func GetSettingsPath() string {
+	return filepath.Join(GetConfigDir(), "config.yaml")
+}
+
+// GetProfileSettingsPath returns the path to the settings file of a profile.
+// For the default profile this is the global settings file.
+func GetProfileSettingsPath(profile string) string {
+	return filepath.Join(GetProfileDir(profile), "config.yaml")
+}
+
+// LoadSettings reads the global settings file. A missing file yields empty settings.
+func LoadSettings() (*Settings, error) {
+	return LoadSettingsFile(GetSettingsPath())
+}
+
+// LoadSettingsFile reads a settings file. A missing file yields empty settings.
+func LoadSettingsFile(path string) (*Settings, error) {
+	var settings Settings
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &settings, nil
+		}
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&settings); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	for _, key := range SettingKeys {
+		field := key.field(&settings)
+		value, err := key.Validate(*field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in %s: %w", key.Name, path, err)
+		}
+		*field = value
+	}
+	return &settings, nil
+}
+
+// GetSetting returns the effective value of a setting for the active profile:
+// from its environment variable, the profile settings, the global settings,
+// or its default
+func GetSetting(name string) (SettingValue, error) {
+	key, err := LookupSettingKey(name)
+	if err != nil {
+		return SettingValue{}, err
+	}
+
+	if env := os.Getenv(key.Env); env != "" {
+		value, err := key.Validate(env)
+		if err != nil {
+			return SettingValue{}, fmt.Errorf("invalid %s in $%s: %w", key.Name, key.Env, err)
+		}
+		return SettingValue{Key: name, Value: value, Source: SettingSourceEnv}, nil
+	}
+
+	if profile := GetProfile(); profile != DefaultProfile {
+		settings, err := LoadSettingsFile(GetProfileSettingsPath(profile))
+		if err != nil {
+			return SettingValue{}, err
+		}
+		if value := *key.field(settings); value != "" {
+			return SettingValue{Key: name, Value: value, Source: SettingSourceProfile}, nil
+		}
+	}
+
+	settings, err := LoadSettings()
+	if err != nil {
+		return SettingValue{}, err
+	}
+	if value := *key.field(settings); value != "" {
+		return SettingValue{Key: name, Value: value, Source: SettingSourceGlobal}, nil
+	}
+
+	return SettingValue{Key: name, Value: key.Default, Source: SettingSourceDefault}, nil
+}
+
+// SetSetting writes a setting to a settings file, keeping the rest of the
+// file and its comments. An empty value removes the setting.
+func SetSetting(path, name, value string) error {
+	key, err := LookupSettingKey(name)
+	if err != nil {
+		return err
+	}
+	value, err = key.Validate(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read settings: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	// A file without settings may still have comments, which are kept
+	var out []byte
+	if len(doc.Content) == 0 {
+		out = bytes.TrimRight(data, "\n")
+		if len(out) > 0 {
+			out = append(out, "\n\n"...)
+		}
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("failed to parse %s: not a mapping", path)
+	}
+
+	found := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != name {
+			continue
+		}
+		if value == "" {
+			root.Content = append(root.Content[:i], root.Content[i+2:]...)
+		} else {
+			root.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Value: value, LineComment: root.Content[i+1].LineComment}
+		}
+		found = true
+		break
+	}
+	if !found && value != "" {
+		root.Content = append(root.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: name},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: value})
+	}
+
+	if len(root.Content) > 0 {
+		settings, err := yaml.Marshal(&doc)
+		if err != nil {
+			return fmt.Errorf("failed to marshal settings: %w", err)
+		}
+		out = append(out, settings...)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, out, 0600); err != nil {
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+	return nil
+}
+
+// GetCredentialStore returns the configured credential store, from
+// FRANKIE_CREDENTIAL_STORE or the settings files, or "" for the default
+func GetCredentialStore() (string, error) {
+	value, err := GetSetting(SettingCredentialStore)
+	if err != nil {
+		return "", err
+	}
+	if value.Source == SettingSourceDefault {
+		return "", nil
+	}
+	return value.Value, nil
+}
+
+func validateCountry(value string) (string, error) {
+	value = strings.ToUpper(value)
+	if value != "NL" && value != "BE" {
+		return "", fmt.Errorf("'%s' is not NL or BE", value)
+	}
+	return value, nil
+}
+
+func validateResolution(value string) (string, error) {
+	if value != "15" && value != "60" {
+		return "", fmt.Errorf("'%s' is not 15 or 60", value)
+	}
+	return value, nil
+}
+
+func validateTimezone(value string) (string, error) {
+	if _, err := time.LoadLocation(value); err != nil {
+		return "", fmt.Errorf("unknown timezone '%s'", value)
+	}
+	return value, nil
+}
+
+func validateDuration(value string) (string, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return "", fmt.Errorf("'%s' is not a duration (e.g. 30s, 5m or 1h)", value)
+	}
+	return value, nil
+}
+
+func validateCredentialStore(value string) (string, error) {
+	switch value {
+	case "file", "keyring", "encrypted-file":
+		return value, nil
+	}
+	return "", fmt.Errorf("'%s' is not file, keyring or encrypted-file", value)
+}
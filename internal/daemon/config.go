@@ -0,0 +1,148 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Actions of jobs
+const (
+	ActionSync     = "sync"
+	ActionChargers = "chargers"
+	ActionVehicles = "vehicles"
+)
+
+// Config is the daemon configuration file
+type Config struct {
+	// Listen is the address of the status endpoint; empty disables it
+	Listen string `yaml:"listen"`
+	// Database is the path of the history database (default: history.db in the data directory)
+	Database string `yaml:"database"`
+	// Resolution is the public price resolution in minutes
+	Resolution int         `yaml:"resolution"`
+	Log        LogConfig   `yaml:"log"`
+	Jobs       []JobConfig `yaml:"jobs"`
+}
+
+// LogConfig configures the structured logs
+type LogConfig struct {
+	// Format is "text" or "json"
+	Format string `yaml:"format"`
+	// Level is "debug", "info", "warn" or "error"
+	Level string `yaml:"level"`
+	// File is the log file, relative to the state directory; logs are
+	// written to stderr if it is empty
+	File string `yaml:"file"`
+}
+
+// JobConfig describes a single scheduled job
+type JobConfig struct {
+	Name     string        `yaml:"name"`
+	Action   string        `yaml:"action"`
+	Datasets []string      `yaml:"datasets,omitempty"`
+	Schedule string        `yaml:"schedule"`
+	Jitter   time.Duration `yaml:"jitter,omitempty"`
+}
+
+// DefaultConfig returns the configuration used when no file exists
+func DefaultConfig() *Config {
+	return &Config{
+		Listen:     "127.0.0.1:9470",
+		Resolution: 60,
+		Log:        LogConfig{Format: "text", Level: "info"},
+		Jobs: []JobConfig{
+			// Day-ahead prices are published around 12:55 CET
+			{Name: "prices", Action: ActionSync, Datasets: []string{"prices", "site-prices"}, Schedule: "daily 13:15", Jitter: 10 * time.Minute},
+			{Name: "usage", Action: ActionSync, Datasets: []string{"usage", "invoices", "battery-sessions"}, Schedule: "daily 03:00", Jitter: 30 * time.Minute},
+			{Name: "summary", Action: ActionSync, Datasets: []string{"summaries"}, Schedule: "every 1h", Jitter: 5 * time.Minute},
+			{Name: "chargers", Action: ActionChargers, Schedule: "every 1m", Jitter: 10 * time.Second},
+		},
+	}
+}
+
+// LoadConfig reads a configuration file. Unset top-level settings keep their
+// defaults; jobs replace the default jobs entirely. If the file does not exist
+// and required is false, the default configuration is returned.
+func LoadConfig(path string, required bool) (*Config, error) {
+	cfg := DefaultConfig()
+
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !required {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	defer f.Close()
+
+	jobs := cfg.Jobs
+	cfg.Jobs = nil
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if cfg.Jobs == nil {
+		cfg.Jobs = jobs
+	}
+
+	return cfg, cfg.validate()
+}
+
+func (c *Config) validate() error {
+	if _, err := c.Log.handler(io.Discard); err != nil {
+		return err
+	}
+
+	names := make(map[string]bool)
+	for i, job := range c.Jobs {
+		if job.Name == "" {
+			return fmt.Errorf("job %d has no name", i+1)
+		}
+		if names[job.Name] {
+			return fmt.Errorf("duplicate job name: %s", job.Name)
+		}
+		names[job.Name] = true
+		if _, err := ParseSchedule(job.Schedule); err != nil {
+			return fmt.Errorf("job %s: %w", job.Name, err)
+		}
+		if job.Jitter < 0 {
+			return fmt.Errorf("job %s: jitter must not be negative", job.Name)
+		}
+	}
+	return nil
+}
+
+// Logger creates a logger that writes to w in the configured format
+func (c LogConfig) Logger(w io.Writer) (*slog.Logger, error) {
+	h, err := c.handler(w)
+	if err != nil {
+		return nil, err
+	}
+	return slog.New(h), nil
+}
+
+func (c LogConfig) handler(w io.Writer) (slog.Handler, error) {
+	var level slog.Level
+	if c.Level != "" {
+		if err := level.UnmarshalText([]byte(c.Level)); err != nil {
+			return nil, fmt.Errorf("invalid log level: %s", c.Level)
+		}
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(c.Format) {
+	case "", "text":
+		return slog.NewTextHandler(w, opts), nil
+	case "json":
+		return slog.NewJSONHandler(w, opts), nil
+	}
+	return nil, fmt.Errorf("invalid log format: %s (must be text or json)", c.Format)
+}
@@ -0,0 +1,133 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	defaults := DefaultConfig()
+
+	tests := []struct {
+		name     string
+		content  *string
+		required bool
+		wantErr  string
+		check    func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "missing",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Listen != defaults.Listen || len(cfg.Jobs) != len(defaults.Jobs) {
+					t.Errorf("got %+v, want the defaults", cfg)
+				}
+			},
+		},
+		{
+			name:     "missing but required",
+			required: true,
+			wantErr:  "failed to read config",
+		},
+		{
+			name:    "empty",
+			content: ptr(""),
+			check: func(t *testing.T, cfg *Config) {
+				if len(cfg.Jobs) != len(defaults.Jobs) {
+					t.Errorf("got %d jobs, want the %d default jobs", len(cfg.Jobs), len(defaults.Jobs))
+				}
+			},
+		},
+		{
+			name:    "top-level settings keep other defaults",
+			content: ptr("listen: ''\nlog:\n  format: json\n"),
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Listen != "" {
+					t.Errorf("Listen = %q, want empty", cfg.Listen)
+				}
+				if cfg.Log.Format != "json" || cfg.Resolution != defaults.Resolution {
+					t.Errorf("got %+v", cfg)
+				}
+				if len(cfg.Jobs) != len(defaults.Jobs) {
+					t.Errorf("got %d jobs, want the %d default jobs", len(cfg.Jobs), len(defaults.Jobs))
+				}
+			},
+		},
+		{
+			name:    "jobs replace the defaults",
+			content: ptr("jobs:\n  - name: vehicles\n    action: vehicles\n    schedule: every 5m\n    jitter: 30s\n"),
+			check: func(t *testing.T, cfg *Config) {
+				if len(cfg.Jobs) != 1 {
+					t.Fatalf("got %d jobs, want 1", len(cfg.Jobs))
+				}
+				job := cfg.Jobs[0]
+				if job.Name != "vehicles" || job.Action != ActionVehicles || job.Schedule != "every 5m" || job.Jitter.Seconds() != 30 {
+					t.Errorf("got %+v", job)
+				}
+			},
+		},
+		{
+			name:    "unknown field",
+			content: ptr("listn: ':9470'\n"),
+			wantErr: "field listn not found",
+		},
+		{
+			name:    "invalid schedule",
+			content: ptr("jobs:\n  - name: sync\n    action: sync\n    schedule: hourly\n"),
+			wantErr: "job sync: invalid schedule",
+		},
+		{
+			name:    "duplicate job",
+			content: ptr("jobs:\n  - name: sync\n    schedule: every 1h\n  - name: sync\n    schedule: every 2h\n"),
+			wantErr: "duplicate job name: sync",
+		},
+		{
+			name:    "job without name",
+			content: ptr("jobs:\n  - schedule: every 1h\n"),
+			wantErr: "job 1 has no name",
+		},
+		{
+			name:    "negative jitter",
+			content: ptr("jobs:\n  - name: sync\n    schedule: every 1h\n    jitter: -1m\n"),
+			wantErr: "jitter must not be negative",
+		},
+		{
+			name:    "invalid log level",
+			content: ptr("log:\n  level: loud\n"),
+			wantErr: "invalid log level: loud",
+		},
+		{
+			name:    "invalid log format",
+			content: ptr("log:\n  format: xml\n"),
+			wantErr: "invalid log format: xml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "daemon.yaml")
+			if tt.content != nil {
+				if err := os.WriteFile(path, []byte(*tt.content), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cfg, err := LoadConfig(path, tt.required)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadConfig error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
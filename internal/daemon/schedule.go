@@ -0,0 +1,58 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Schedule determines when a job runs: at a fixed interval ("every 15m") or
+// at a time of day ("daily 13:15")
+type Schedule struct {
+	spec  string
+	every time.Duration
+	// minute is the minute of the day of a daily schedule
+	minute int
+	daily  bool
+}
+
+// ParseSchedule parses "every <duration>" or "daily HH:MM"
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.Join(strings.Fields(spec), " ")
+	kind, value, _ := strings.Cut(spec, " ")
+
+	switch kind {
+	case "every":
+		d, err := time.ParseDuration(value)
+		if err != nil || d < time.Minute {
+			return Schedule{}, fmt.Errorf("invalid schedule '%s' (interval must be at least 1m)", spec)
+		}
+		return Schedule{spec: spec, every: d}, nil
+	case "daily":
+		t, err := time.Parse("15:04", value)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("invalid schedule '%s' (expected daily HH:MM)", spec)
+		}
+		return Schedule{spec: spec, daily: true, minute: t.Hour()*60 + t.Minute()}, nil
+	}
+	return Schedule{}, fmt.Errorf("invalid schedule '%s' (expected 'every <duration>' or 'daily HH:MM')", spec)
+}
+
+// Next returns the first time after t that the schedule is due. Daily times
+// are interpreted in the location of t.
+func (s Schedule) Next(t time.Time) time.Time {
+	if !s.daily {
+		return t.Add(s.every)
+	}
+
+	y, m, d := t.Date()
+	next := time.Date(y, m, d, s.minute/60, s.minute%60, 0, 0, t.Location())
+	if !next.After(t) {
+		next = time.Date(y, m, d+1, s.minute/60, s.minute%60, 0, 0, t.Location())
+	}
+	return next
+}
+
+func (s Schedule) String() string {
+	return s.spec
+}
@@ -0,0 +1,107 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{spec: "every 15m", want: "every 15m"},
+		{spec: "  every   1h30m ", want: "every 1h30m"},
+		{spec: "daily 13:15", want: "daily 13:15"},
+		{spec: "daily 00:00", want: "daily 00:00"},
+		{spec: "every 30s", wantErr: true},
+		{spec: "every soon", wantErr: true},
+		{spec: "daily 25:00", wantErr: true},
+		{spec: "daily 1pm", wantErr: true},
+		{spec: "hourly", wantErr: true},
+		{spec: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := ParseSchedule(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseSchedule(%q) = %v, want error", tt.spec, s)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q): %v", tt.spec, err)
+			}
+			if s.String() != tt.want {
+				t.Errorf("String() = %q, want %q", s.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Skipf("no time zone database: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		spec string
+		t    time.Time
+		want time.Time
+	}{
+		{
+			name: "interval",
+			spec: "every 15m",
+			t:    time.Date(2026, 10, 18, 10, 7, 30, 0, time.UTC),
+			want: time.Date(2026, 10, 18, 10, 22, 30, 0, time.UTC),
+		},
+		{
+			name: "later today",
+			spec: "daily 13:15",
+			t:    time.Date(2026, 10, 18, 9, 0, 0, 0, amsterdam),
+			want: time.Date(2026, 10, 18, 13, 15, 0, 0, amsterdam),
+		},
+		{
+			name: "due now runs tomorrow",
+			spec: "daily 13:15",
+			t:    time.Date(2026, 10, 18, 13, 15, 0, 0, amsterdam),
+			want: time.Date(2026, 10, 19, 13, 15, 0, 0, amsterdam),
+		},
+		{
+			name: "passed today",
+			spec: "daily 03:00",
+			t:    time.Date(2026, 10, 18, 22, 0, 0, 0, amsterdam),
+			want: time.Date(2026, 10, 19, 3, 0, 0, 0, amsterdam),
+		},
+		{
+			name: "end of month",
+			spec: "daily 00:30",
+			t:    time.Date(2026, 10, 31, 12, 0, 0, 0, amsterdam),
+			want: time.Date(2026, 11, 1, 0, 30, 0, 0, amsterdam),
+		},
+		{
+			// Clocks move from 02:00 to 03:00, so the day is 23 hours long
+			name: "daylight saving time",
+			spec: "daily 03:00",
+			t:    time.Date(2026, 3, 28, 4, 0, 0, 0, amsterdam),
+			want: time.Date(2026, 3, 29, 3, 0, 0, 0, amsterdam),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Next(tt.t); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package daemon
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Job is a function that runs on a schedule
+type Job struct {
+	Name     string
+	Schedule Schedule
+	// Jitter is the maximum random delay added to every run
+	Jitter time.Duration
+	Run    func(ctx context.Context) error
+}
+
+// JobStatus is the state of a job as reported by the status endpoint
+type JobStatus struct {
+	Name         string     `json:"name"`
+	Schedule     string     `json:"schedule"`
+	Running      bool       `json:"running"`
+	LastRun      *time.Time `json:"lastRun"`
+	LastDuration string     `json:"lastDuration,omitempty"`
+	LastError    string     `json:"lastError,omitempty"`
+	LastSuccess  *time.Time `json:"lastSuccess"`
+	NextRun      *time.Time `json:"nextRun"`
+	Runs         int        `json:"runs"`
+	Failures     int        `json:"failures"`
+}
+
+// Scheduler runs jobs on their schedules. Every job runs once at startup and
+// then whenever its schedule is due. A job never overlaps with itself.
+type Scheduler struct {
+	loc  *time.Location
+	jobs []*scheduledJob
+
+	mu sync.Mutex
+}
+
+type scheduledJob struct {
+	Job
+	status JobStatus
+}
+
+// NewScheduler creates a scheduler that interprets daily schedules in loc
+func NewScheduler(loc *time.Location) *Scheduler {
+	return &Scheduler{loc: loc}
+}
+
+// Add registers a job. Jobs must be added before Run is called.
+func (s *Scheduler) Add(job Job) {
+	s.jobs = append(s.jobs, &scheduledJob{
+		Job:    job,
+		status: JobStatus{Name: job.Name, Schedule: job.Schedule.String()},
+	})
+}
+
+// Run runs all jobs until the context is done, then waits for running jobs
+// to finish
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}()
+	}
+	wg.Wait()
+}
+
+// Status returns the state of all jobs
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		statuses = append(statuses, job.status)
+	}
+	return statuses
+}
+
+func (s *Scheduler) loop(ctx context.Context, job *scheduledJob) {
+	next := time.Now()
+	for {
+		at := next.Add(jitter(job.Jitter))
+		s.setNextRun(job, at)
+
+		timer := time.NewTimer(time.Until(at))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.setNextRun(job, time.Time{})
+			return
+		case <-timer.C:
+		}
+
+		s.runOnce(ctx, job)
+		next = job.Schedule.Next(time.Now().In(s.loc)).Local()
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, job *scheduledJob) {
+	start := time.Now()
+	s.mu.Lock()
+	job.status.Running = true
+	job.status.LastRun = &start
+	s.mu.Unlock()
+
+	slog.Debug("job started", "job", job.Name)
+	err := job.Run(ctx)
+	duration := time.Since(start).Round(time.Millisecond)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job.status.Running = false
+	job.status.LastDuration = duration.String()
+	job.status.Runs++
+	if err != nil {
+		job.status.Failures++
+		job.status.LastError = err.Error()
+		slog.Error("job failed", "job", job.Name, "duration", duration.String(), "error", err)
+		return
+	}
+	job.status.LastError = ""
+	job.status.LastSuccess = &start
+	slog.Info("job finished", "job", job.Name, "duration", duration.String())
+}
+
+func (s *Scheduler) setNextRun(job *scheduledJob, t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t.IsZero() {
+		job.status.NextRun = nil
+		return
+	}
+	job.status.NextRun = &t
+}
+
+// jitter returns a random delay of up to max
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return rand.N(max)
+}
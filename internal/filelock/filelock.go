@@ -0,0 +1,60 @@
+package filelock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// pollInterval is how often a held lock is retried
+const pollInterval = 50 * time.Millisecond
+
+// errLocked is returned by tryLock when another process holds the lock
+var errLocked = errors.New("locked")
+
+// Lock is an exclusive advisory lock on a file, held until Unlock is called.
+// Locks exclude other processes; within a process, callers must serialize
+// themselves.
+type Lock struct {
+	f *os.File
+}
+
+// Acquire locks the file at path, creating it if needed. It waits up to
+// timeout for another process to release the lock.
+func Acquire(path string, timeout time.Duration) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := tryLock(f)
+		if err == nil {
+			return &Lock{f: f}, nil
+		}
+		if !errors.Is(err, errLocked) {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("timed out waiting for lock on %s", path)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// Unlock releases the lock
+func (l *Lock) Unlock() error {
+	err := unlock(l.f)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
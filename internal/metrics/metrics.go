@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the content type of the Prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Label is a metric label
+type Label struct {
+	Name  string
+	Value string
+}
+
+// L creates a label
+func L(name, value string) Label {
+	return Label{Name: name, Value: value}
+}
+
+type sample struct {
+	labels []Label
+	value  float64
+}
+
+type family struct {
+	name    string
+	help    string
+	samples []sample
+}
+
+// Set collects gauges for a single scrape, in the order they are added
+type Set struct {
+	families []*family
+	index    map[string]*family
+}
+
+// NewSet creates an empty metric set
+func NewSet() *Set {
+	return &Set{index: make(map[string]*family)}
+}
+
+// Gauge adds a gauge sample. Samples with the same name share a family,
+// and the help text of the first sample is used.
+func (s *Set) Gauge(name, help string, value float64, labels ...Label) {
+	f, ok := s.index[name]
+	if !ok {
+		f = &family{name: name, help: help}
+		s.index[name] = f
+		s.families = append(s.families, f)
+	}
+	f.samples = append(f.samples, sample{labels: labels, value: value})
+}
+
+// Bool adds a gauge that is 1 for true and 0 for false
+func (s *Set) Bool(name, help string, value bool, labels ...Label) {
+	v := 0.0
+	if value {
+		v = 1
+	}
+	s.Gauge(name, help, v, labels...)
+}
+
+// WriteTo writes the set in the Prometheus text exposition format
+func (s *Set) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+
+	for _, f := range s.families {
+		fmt.Fprintf(bw, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		fmt.Fprintf(bw, "# TYPE %s gauge\n", f.name)
+		for _, sm := range f.samples {
+			bw.WriteString(f.name)
+			if len(sm.labels) > 0 {
+				bw.WriteByte('{')
+				for i, l := range sm.labels {
+					if i > 0 {
+						bw.WriteByte(',')
+					}
+					fmt.Fprintf(bw, "%s=\"%s\"", l.Name, escapeLabel(l.Value))
+				}
+				bw.WriteByte('}')
+			}
+			bw.WriteByte(' ')
+			bw.WriteString(formatValue(sm.value))
+			bw.WriteByte('\n')
+		}
+	}
+
+	err := bw.Flush()
+	return cw.n, err
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestWriteTo(t *testing.T) {
+	tests := []struct {
+		name string
+		add  func(s *Set)
+		want string
+	}{
+		{
+			name: "empty",
+			add:  func(s *Set) {},
+			want: "",
+		},
+		{
+			name: "gauge without labels",
+			add: func(s *Set) {
+				s.Gauge("frankie_up", "Whether the last refresh succeeded.", 1)
+			},
+			want: `# HELP frankie_up Whether the last refresh succeeded.
+# TYPE frankie_up gauge
+frankie_up 1
+`,
+		},
+		{
+			name: "samples share a family in order",
+			add: func(s *Set) {
+				s.Gauge("frankie_price_euros", "Price.", 0.25, L("segment", "electricity"))
+				s.Bool("frankie_reachable", "Reachable.", true, L("charger", "c1"))
+				s.Gauge("frankie_price_euros", "Ignored.", 1.5, L("segment", "gas"))
+				s.Bool("frankie_reachable", "Reachable.", false, L("charger", "c2"))
+			},
+			want: `# HELP frankie_price_euros Price.
+# TYPE frankie_price_euros gauge
+frankie_price_euros{segment="electricity"} 0.25
+frankie_price_euros{segment="gas"} 1.5
+# HELP frankie_reachable Reachable.
+# TYPE frankie_reachable gauge
+frankie_reachable{charger="c1"} 1
+frankie_reachable{charger="c2"} 0
+`,
+		},
+		{
+			name: "escaping",
+			add: func(s *Set) {
+				s.Gauge("frankie_info", "Back\\slash and\nnewline.", 1, L("model", "Model \"3\"\n\\"), L("brand", ""))
+			},
+			want: `# HELP frankie_info Back\\slash and\nnewline.
+# TYPE frankie_info gauge
+frankie_info{model="Model \"3\"\n\\",brand=""} 1
+`,
+		},
+		{
+			name: "special values",
+			add: func(s *Set) {
+				s.Gauge("v", "V.", math.NaN(), L("k", "nan"))
+				s.Gauge("v", "V.", math.Inf(1), L("k", "inf"))
+				s.Gauge("v", "V.", math.Inf(-1), L("k", "-inf"))
+				s.Gauge("v", "V.", 1e21, L("k", "large"))
+				s.Gauge("v", "V.", -0.000125, L("k", "small"))
+			},
+			want: `# HELP v V.
+# TYPE v gauge
+v{k="nan"} NaN
+v{k="inf"} +Inf
+v{k="-inf"} -Inf
+v{k="large"} 1e+21
+v{k="small"} -0.000125
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSet()
+			tt.add(s)
+
+			var b strings.Builder
+			n, err := s.WriteTo(&b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+			if n != int64(b.Len()) {
+				t.Errorf("WriteTo returned %d, wrote %d bytes", n, b.Len())
+			}
+		})
+	}
+}
@@ -1,16 +1,25 @@
 package models
 
-// ChargeSettings represents charging configuration
+// ChargeSettings represents charging configuration. The hour of a weekday is
+// the time charging should be done by on that day, in minutes after midnight.
 type ChargeSettings struct {
 	ID                     string  `json:"id"`
 	CalculatedDeadline     string  `json:"calculatedDeadline"`
 	Capacity               float64 `json:"capacity"`
 	Deadline               string  `json:"deadline"`
+	HourMonday             int     `json:"hourMonday"`
+	HourTuesday            int     `json:"hourTuesday"`
+	HourWednesday          int     `json:"hourWednesday"`
+	HourThursday           int     `json:"hourThursday"`
+	HourFriday             int     `json:"hourFriday"`
+	HourSaturday           int     `json:"hourSaturday"`
+	HourSunday             int     `json:"hourSunday"`
 	IsSmartChargingEnabled bool    `json:"isSmartChargingEnabled"`
 	IsSolarChargingEnabled bool    `json:"isSolarChargingEnabled"`
 	MaxChargeLimit         float64 `json:"maxChargeLimit"`
 	MinChargeLimit         float64 `json:"minChargeLimit"`
 	InitialCharge          float64 `json:"initialCharge"`
+	InitialChargeTimestamp string  `json:"initialChargeTimestamp"`
 }
 
 // ChargeState represents current charging state
@@ -5,19 +5,160 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
+	"github.com/jmespath/go-jmespath"
 )
 
 // Format represents the output format type
 type Format string
 
 const (
-	FormatTable Format = "table"
-	FormatJSON  Format = "json"
+	FormatTable  Format = "table"
+	FormatJSON   Format = "json"
+	FormatCSV    Format = "csv"
+	FormatTSV    Format = "tsv"
+	FormatNDJSON Format = "ndjson"
+	FormatYAML   Format = "yaml"
+	FormatInflux Format = "influx"
 )
 
+// FormatTemplate executes a Go template for every record. It is selected
+// with "template=<template>" or "template-file=<path>".
+const FormatTemplate Format = "template"
+
+// Formats lists all supported output formats
+var Formats = []Format{FormatTable, FormatJSON, FormatYAML, FormatCSV, FormatTSV, FormatNDJSON, FormatInflux, FormatTemplate}
+
+// Options controls how command output is rendered
+type Options struct {
+	Format   Format
+	Template *template.Template
+	Columns  []string
+	Query    *jmespath.JMESPath
+}
+
+// ParseOptions parses an output format specification ("csv",
+// "template={{.From}}", "template-file=path") and the selected columns.
+func ParseOptions(spec string, columns []string) (*Options, error) {
+	opts := &Options{Columns: columns}
+
+	name, arg, hasArg := strings.Cut(spec, "=")
+	switch {
+	case name == "template" && hasArg:
+		tmpl, err := template.New("output").Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %w", err)
+		}
+		opts.Format = FormatTemplate
+		opts.Template = tmpl
+	case name == "template-file" && hasArg:
+		data, err := os.ReadFile(arg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		tmpl, err := template.New(filepath.Base(arg)).Parse(strings.TrimRight(string(data), "\n"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %w", err)
+		}
+		opts.Format = FormatTemplate
+		opts.Template = tmpl
+	default:
+		for _, f := range Formats {
+			if string(f) == spec && f != FormatTemplate {
+				opts.Format = f
+			}
+		}
+		if opts.Format == "" {
+			return nil, fmt.Errorf("invalid output format: %s (must be one of table, json, yaml, csv, tsv, ndjson, influx, template=<template> or template-file=<path>)", spec)
+		}
+	}
+
+	// Other formats write every field, so selected columns would be ignored
+	if len(columns) > 0 && opts.Format != FormatTable && opts.Format != FormatCSV && opts.Format != FormatTSV {
+		return nil, fmt.Errorf("--columns is not supported with %s output", opts.Format)
+	}
+	return opts, nil
+}
+
+// SetQuery compiles a JMESPath expression to filter the output with.
+// Queries apply to the document, so they work with JSON, YAML and table output.
+func (o *Options) SetQuery(expr string) error {
+	if expr == "" {
+		return nil
+	}
+	if o.Format != FormatTable && o.Format != FormatJSON && o.Format != FormatYAML {
+		return fmt.Errorf("--query is not supported with %s output", o.Format)
+	}
+	if len(o.Columns) > 0 {
+		return fmt.Errorf("--query cannot be combined with --columns")
+	}
+
+	q, err := jmespath.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+	o.Query = q
+	return nil
+}
+
+// Structured reports whether output is rendered from data rather than
+// by a command's own table layout. Selecting columns or querying renders
+// tables from data.
+func (o *Options) Structured() bool {
+	return o.Format != FormatTable || len(o.Columns) > 0 || o.Query != nil
+}
+
+// UsesRecords reports whether records rather than the document are rendered,
+// which is the case for all formats but JSON and YAML, unless querying
+func (o *Options) UsesRecords() bool {
+	return o.Query == nil && o.Format != FormatJSON && o.Format != FormatYAML
+}
+
+// Render writes command output in a machine-readable format. The document is
+// written as-is for JSON and YAML, while the records (a struct, a slice of structs or
+// a Dataset) are written one per row for the other formats. With a query,
+// the filtered document is written instead.
+func Render(w io.Writer, opts *Options, doc, records interface{}) error {
+	if opts.Query != nil {
+		result, err := Query(opts.Query, doc)
+		if err != nil {
+			return err
+		}
+		switch opts.Format {
+		case FormatJSON:
+			return JSONTo(w, result)
+		case FormatYAML:
+			return YAMLTo(w, result)
+		}
+		return ValueTable(w, result)
+	}
+
+	switch opts.Format {
+	case FormatTable:
+		return RecordsTable(w, records, opts.Columns)
+	case FormatJSON:
+		return JSONTo(w, doc)
+	case FormatYAML:
+		return YAMLTo(w, doc)
+	case FormatCSV:
+		return Delimited(w, records, ',', opts.Columns)
+	case FormatTSV:
+		return Delimited(w, records, '\t', opts.Columns)
+	case FormatNDJSON:
+		return NDJSON(w, records)
+	case FormatInflux:
+		return LineProtocol(w, records)
+	case FormatTemplate:
+		return Template(w, opts.Template, records)
+	}
+	return fmt.Errorf("output format %s is not supported here", opts.Format)
+}
+
 // Table prints a table with default styling to stdout
 func Table(headers []string, rows [][]string) {
 	TableTo(os.Stdout, headers, rows)
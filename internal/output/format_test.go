@@ -0,0 +1,54 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		spec    string
+		columns []string
+		query   string
+		want    Format
+		wantErr string
+	}{
+		{spec: "table", want: FormatTable},
+		{spec: "csv", columns: []string{"from"}, want: FormatCSV},
+		{spec: "tsv", columns: []string{"from"}, want: FormatTSV},
+		{spec: "table", columns: []string{"from"}, want: FormatTable},
+		{spec: "template={{.From}}", want: FormatTemplate},
+		{spec: "json", query: "[0]", want: FormatJSON},
+		{spec: "xml", wantErr: "invalid output format: xml"},
+		{spec: "template", wantErr: "invalid output format: template"},
+		{spec: "template={{.From", wantErr: "invalid template"},
+		{spec: "json", columns: []string{"from"}, wantErr: "--columns is not supported with json output"},
+		{spec: "ndjson", columns: []string{"from"}, wantErr: "--columns is not supported with ndjson output"},
+		{spec: "template={{.}}", columns: []string{"from"}, wantErr: "--columns is not supported with template output"},
+		{spec: "csv", query: "[0]", wantErr: "--query is not supported with csv output"},
+		{spec: "table", columns: []string{"from"}, query: "[0]", wantErr: "--query cannot be combined with --columns"},
+		{spec: "json", query: "[", wantErr: "invalid query"},
+	}
+
+	for _, tt := range tests {
+		name := tt.spec + " " + strings.Join(tt.columns, ",") + " " + tt.query
+		t.Run(name, func(t *testing.T) {
+			opts, err := ParseOptions(tt.spec, tt.columns)
+			if err == nil {
+				err = opts.SetQuery(tt.query)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if opts.Format != tt.want {
+				t.Errorf("Format = %s, want %s", opts.Format, tt.want)
+			}
+		})
+	}
+}
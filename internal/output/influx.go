@@ -0,0 +1,137 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Point is a single point in InfluxDB line protocol
+type Point struct {
+	Measurement string
+	Tags        []Tag
+	Fields      []Field
+	Time        time.Time
+}
+
+// Tag is an indexed key-value pair of a point. Tags with empty values are omitted.
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// Field is a value of a point: a float, integer, bool or string
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// InfluxRecord is implemented by records that can be written as line protocol
+type InfluxRecord interface {
+	InfluxPoint() Point
+}
+
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	keyEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+	stringEscaper      = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+)
+
+// LineProtocol writes records as InfluxDB line protocol with nanosecond
+// timestamps. Records must be a record or slice of records that implement
+// InfluxRecord.
+func LineProtocol(w io.Writer, records interface{}) error {
+	v := reflect.ValueOf(records)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	items := []reflect.Value{v}
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		items = items[:0]
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, v.Index(i))
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, item := range items {
+		record, ok := item.Interface().(InfluxRecord)
+		if !ok {
+			return fmt.Errorf("influx output is not supported by this command")
+		}
+		if line := record.InfluxPoint().Line(); line != "" {
+			bw.WriteString(line)
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
+// Line formats the point as a line of line protocol, without a trailing
+// newline. Points without fields cannot be written and yield "".
+func (p Point) Line() string {
+	var fields []string
+	for _, f := range p.Fields {
+		value, ok := formatField(f.Value)
+		if !ok {
+			continue
+		}
+		fields = append(fields, keyEscaper.Replace(f.Key)+"="+value)
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+
+	tags := make([]Tag, 0, len(p.Tags))
+	for _, t := range p.Tags {
+		if t.Value != "" {
+			tags = append(tags, t)
+		}
+	}
+	// Sorted tags are recommended for write performance
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Key < tags[j].Key })
+
+	var b strings.Builder
+	b.WriteString(measurementEscaper.Replace(p.Measurement))
+	for _, t := range tags {
+		b.WriteByte(',')
+		b.WriteString(keyEscaper.Replace(t.Key))
+		b.WriteByte('=')
+		b.WriteString(keyEscaper.Replace(t.Value))
+	}
+	b.WriteByte(' ')
+	b.WriteString(strings.Join(fields, ","))
+	if !p.Time.IsZero() {
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatInt(p.Time.UnixNano(), 10))
+	}
+	return b.String()
+}
+
+// formatField formats a field value, reporting false for unsupported types
+func formatField(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), true
+	case int:
+		return strconv.Itoa(v) + "i", true
+	case int64:
+		return strconv.FormatInt(v, 10) + "i", true
+	case bool:
+		return strconv.FormatBool(v), true
+	case string:
+		return `"` + stringEscaper.Replace(v) + `"`, true
+	}
+	return "", false
+}
@@ -0,0 +1,119 @@
+package output
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPointLine(t *testing.T) {
+	at := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		point Point
+		want  string
+	}{
+		{
+			name: "sorted tags and typed fields",
+			point: Point{
+				Measurement: "usage",
+				Tags:        []Tag{{"segment", "electricity"}, {"ean", "871"}},
+				Fields:      []Field{{"usage", 1.5}, {"count", 3}, {"total", int64(-2)}, {"complete", true}, {"unit", "KWH"}},
+				Time:        at,
+			},
+			want: `usage,ean=871,segment=electricity usage=1.5,count=3i,total=-2i,complete=true,unit="KWH" 1792324800000000000`,
+		},
+		{
+			name: "escaping",
+			point: Point{
+				Measurement: "my measurement,x",
+				Tags:        []Tag{{"site reference", "1234AB 5,a=b"}},
+				Fields:      []Field{{"note", `say "hi" \o/`}},
+			},
+			want: `my\ measurement\,x,site\ reference=1234AB\ 5\,a\=b note="say \"hi\" \\o/"`,
+		},
+		{
+			name: "empty tags and unsupported fields are left out",
+			point: Point{
+				Measurement: "prices",
+				Tags:        []Tag{{"site", ""}},
+				Fields:      []Field{{"price", 0.25}, {"raw", []int{1}}},
+			},
+			want: "prices price=0.25",
+		},
+		{
+			name: "no fields",
+			point: Point{
+				Measurement: "prices",
+				Fields:      []Field{{"raw", nil}},
+				Time:        at,
+			},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.point.Line(); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// testPoint is a record that can be written as line protocol
+type testPoint struct {
+	Value float64
+}
+
+func (p testPoint) InfluxPoint() Point {
+	if p.Value < 0 {
+		return Point{Measurement: "test"}
+	}
+	return Point{Measurement: "test", Fields: []Field{{"value", p.Value}}}
+}
+
+func TestLineProtocol(t *testing.T) {
+	tests := []struct {
+		name    string
+		records interface{}
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "slice",
+			records: []testPoint{{1}, {-1}, {2.5}},
+			want:    "test value=1\ntest value=2.5\n",
+		},
+		{
+			name:    "single record",
+			records: &testPoint{3},
+			want:    "test value=3\n",
+		},
+		{
+			name:    "unsupported records",
+			records: []testInfo{{Brand: "Zaptec"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			err := LineProtocol(&b, tt.records)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
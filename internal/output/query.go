@@ -0,0 +1,101 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/jmespath/go-jmespath"
+)
+
+// Query filters a document with a JMESPath expression. The document is
+// queried as its JSON encoding, so expressions use the JSON field names.
+func Query(expr *jmespath.JMESPath, doc interface{}) (interface{}, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	result, err := expr.Search(value)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	return result, nil
+}
+
+// ValueTable prints an arbitrary JSON value: a list of objects as a table,
+// an object as key-value pairs, and scalars (or lists of scalars) one per line.
+func ValueTable(w io.Writer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		keys := sortedKeys(v)
+		pairs := make(map[string]string, len(v))
+		for _, k := range keys {
+			pairs[k] = FormatValue(v[k])
+		}
+		KeyValueOrderedTo(w, keys, pairs)
+		return nil
+	case []interface{}:
+		if !allObjects(v) {
+			for _, item := range v {
+				if _, err := fmt.Fprintln(w, FormatValue(item)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		// Columns are the union of all keys, as objects may differ
+		seen := make(map[string]interface{})
+		for _, item := range v {
+			for k := range item.(map[string]interface{}) {
+				seen[k] = nil
+			}
+		}
+		headers := sortedKeys(seen)
+
+		rows := make([][]string, len(v))
+		for i, item := range v {
+			obj := item.(map[string]interface{})
+			rows[i] = make([]string, len(headers))
+			for j, k := range headers {
+				rows[i][j] = FormatValue(obj[k])
+			}
+		}
+		TableTo(w, headers, rows)
+		return nil
+	}
+
+	_, err := fmt.Fprintln(w, FormatValue(value))
+	return err
+}
+
+// allObjects reports whether a non-empty list only contains objects
+func allObjects(list []interface{}) bool {
+	if len(list) == 0 {
+		return false
+	}
+	for _, item := range list {
+		if _, ok := item.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,445 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// listSeparator joins the elements of list values within a single cell
+const listSeparator = ";"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Dataset holds records with explicitly named columns, for data that
+// is not described by a model struct (such as ad-hoc query results)
+type Dataset struct {
+	Columns []string
+	Rows    [][]interface{}
+}
+
+// MarshalJSON encodes the dataset as an array of objects, keeping column order
+func (d Dataset) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, row := range d.Rows {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		obj, err := d.marshalRow(row)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(obj)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+// marshalRow encodes a single row as an object, keeping column order
+func (d Dataset) marshalRow(row []interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for j, col := range d.Columns {
+		if j > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(col)
+		val, err := json.Marshal(row[j])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// column describes a flattened record field
+type column struct {
+	name  string // JSON name, e.g. "information.brand"
+	field string // Go field name, e.g. "Information.Brand"
+	index []int
+}
+
+// Flatten converts records into column names and rows of raw values.
+// Records may be a Dataset, a struct, or a slice of structs. Column names are
+// the JSON field names; fields of nested structs are prefixed with the name
+// of the parent field (e.g. "information.brand").
+func Flatten(records interface{}) ([]string, [][]interface{}, error) {
+	columns, rows, err := flatten(records)
+	if err != nil {
+		return nil, nil, err
+	}
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	return names, rows, nil
+}
+
+// Select flattens records like Flatten, keeping only the requested columns
+// in the requested order. Columns may be given by JSON name or Go field name,
+// case-insensitively. All columns are kept if none are requested.
+func Select(records interface{}, requested []string) ([]string, [][]interface{}, error) {
+	if len(requested) == 0 {
+		return Flatten(records)
+	}
+
+	columns, rows, err := flatten(records)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make([]string, len(requested))
+	indexes := make([]int, len(requested))
+	for i, req := range requested {
+		indexes[i] = -1
+		for j, c := range columns {
+			if strings.EqualFold(req, c.name) || strings.EqualFold(req, c.field) {
+				indexes[i] = j
+				names[i] = c.name
+				break
+			}
+		}
+		if indexes[i] < 0 {
+			available := make([]string, len(columns))
+			for j, c := range columns {
+				available[j] = c.name
+			}
+			return nil, nil, fmt.Errorf("unknown column: %s (available: %s)", req, strings.Join(available, ", "))
+		}
+	}
+
+	selected := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		selected[i] = make([]interface{}, len(indexes))
+		for j, idx := range indexes {
+			selected[i][j] = row[idx]
+		}
+	}
+	return names, selected, nil
+}
+
+// flatten converts records into columns and rows of raw values
+func flatten(records interface{}) ([]column, [][]interface{}, error) {
+	if d, ok := records.(*Dataset); ok {
+		records = *d
+	}
+	if d, ok := records.(Dataset); ok {
+		columns := make([]column, len(d.Columns))
+		for i, name := range d.Columns {
+			columns[i] = column{name: name, field: name}
+		}
+		return columns, d.Rows, nil
+	}
+
+	v := reflect.ValueOf(records)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil, nil
+		}
+		v = v.Elem()
+	}
+
+	var items []reflect.Value
+	var elemType reflect.Type
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		elemType = v.Type().Elem()
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, v.Index(i))
+		}
+	case reflect.Struct:
+		elemType = v.Type()
+		items = []reflect.Value{v}
+	default:
+		return nil, nil, fmt.Errorf("cannot convert %s to records", v.Type())
+	}
+
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct || elemType == timeType {
+		return nil, nil, fmt.Errorf("cannot convert %s to records", v.Type())
+	}
+
+	columns := structColumns(elemType, "", "", nil)
+	rows := make([][]interface{}, 0, len(items))
+	for _, item := range items {
+		row := make([]interface{}, len(columns))
+		for i, c := range columns {
+			row[i] = fieldValue(item, c.index)
+		}
+		rows = append(rows, row)
+	}
+
+	return columns, rows, nil
+}
+
+// structColumns lists the flattened columns of a struct type
+func structColumns(t reflect.Type, prefix, fieldPrefix string, index []int) []column {
+	var columns []column
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, skip := jsonName(f)
+		if skip {
+			continue
+		}
+
+		idx := append(append([]int(nil), index...), i)
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		isStruct := ft.Kind() == reflect.Struct && ft != timeType
+		if f.Anonymous && isStruct && name == "" {
+			// Promote fields of embedded structs, like encoding/json
+			columns = append(columns, structColumns(ft, prefix, fieldPrefix, idx)...)
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		if isStruct {
+			columns = append(columns, structColumns(ft, prefix+name+".", fieldPrefix+f.Name+".", idx)...)
+			continue
+		}
+		columns = append(columns, column{name: prefix + name, field: fieldPrefix + f.Name, index: idx})
+	}
+	return columns
+}
+
+// jsonName returns the JSON name of a field, and whether it is skipped
+func jsonName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	return name, false
+}
+
+// fieldValue follows an index path, returning nil when a pointer along the way is nil
+func fieldValue(v reflect.Value, index []int) interface{} {
+	for _, i := range index {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return v.Interface()
+}
+
+// FormatValue formats a raw value for delimited output: timestamps as
+// ISO-8601, numbers without units or rounding, and lists joined by ';'.
+func FormatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case bool:
+		return strconv.FormatBool(v)
+	case []byte:
+		return string(v)
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return ""
+		}
+		elem := rv.Type().Elem()
+		if elem.Kind() != reflect.Struct && elem.Kind() != reflect.Map && elem.Kind() != reflect.Ptr {
+			parts := make([]string, rv.Len())
+			for i := range parts {
+				parts[i] = FormatValue(rv.Index(i).Interface())
+			}
+			return strings.Join(parts, listSeparator)
+		}
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// Delimited writes records as delimiter-separated values with a header row,
+// optionally limited to the given columns. Fields are quoted according to RFC 4180.
+func Delimited(w io.Writer, records interface{}, comma rune, columns []string) error {
+	columns, rows, err := Select(records, columns)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			cells[i] = FormatValue(v)
+		}
+		if err := cw.Write(cells); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// RecordsTable prints records as a table, optionally limited to the given columns
+func RecordsTable(w io.Writer, records interface{}, columns []string) error {
+	columns, rows, err := Select(records, columns)
+	if err != nil {
+		return err
+	}
+
+	cells := make([][]string, len(rows))
+	for i, row := range rows {
+		cells[i] = make([]string, len(row))
+		for j, v := range row {
+			cells[i][j] = FormatValue(v)
+		}
+	}
+
+	TableTo(w, columns, cells)
+	return nil
+}
+
+// Template executes a template once for every record, each followed by a newline
+func Template(w io.Writer, tmpl *template.Template, records interface{}) error {
+	if d, ok := records.(*Dataset); ok {
+		records = *d
+	}
+	if d, ok := records.(Dataset); ok {
+		// Rows of a dataset are exposed as maps keyed by column name
+		rows := make([]map[string]interface{}, len(d.Rows))
+		for i, row := range d.Rows {
+			rows[i] = make(map[string]interface{}, len(d.Columns))
+			for j, col := range d.Columns {
+				rows[i][col] = row[j]
+			}
+		}
+		records = rows
+	}
+
+	v := reflect.ValueOf(records)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	items := []interface{}{records}
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		items = items[:0]
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, v.Index(i).Interface())
+		}
+	}
+
+	for _, item := range items {
+		if err := tmpl.Execute(w, item); err != nil {
+			return fmt.Errorf("template failed: %w", err)
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NDJSON writes each record as a single line of JSON. Records may be a
+// Dataset, a struct, or a slice of structs.
+func NDJSON(w io.Writer, records interface{}) error {
+	var lines [][]byte
+
+	switch d := records.(type) {
+	case Dataset:
+		for _, row := range d.Rows {
+			line, err := d.marshalRow(row)
+			if err != nil {
+				return err
+			}
+			lines = append(lines, line)
+		}
+	case *Dataset:
+		return NDJSON(w, *d)
+	default:
+		v := reflect.ValueOf(records)
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+
+		items := []reflect.Value{v}
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			items = items[:0]
+			for i := 0; i < v.Len(); i++ {
+				items = append(items, v.Index(i))
+			}
+		}
+
+		for _, item := range items {
+			line, err := json.Marshal(item.Interface())
+			if err != nil {
+				return err
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	for _, line := range lines {
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package output
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type testInfo struct {
+	Brand string `json:"brand"`
+	Model string `json:"model"`
+}
+
+type testRecord struct {
+	Name   string    `json:"name"`
+	Price  float64   `json:"price"`
+	From   time.Time `json:"from"`
+	Tags   []string  `json:"tags"`
+	Info   *testInfo `json:"info"`
+	Hidden string    `json:"-"`
+}
+
+var testRecords = []testRecord{
+	{
+		Name:  "Zaptec",
+		Price: 0.25,
+		From:  time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+		Tags:  []string{"a", "b"},
+		Info:  &testInfo{Brand: "Zaptec", Model: "Go"},
+	},
+	{
+		Name:   "quote \"and\", comma",
+		Price:  1e-7,
+		Hidden: "secret",
+	},
+}
+
+func TestDelimited(t *testing.T) {
+	tests := []struct {
+		name    string
+		records interface{}
+		comma   rune
+		columns []string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "csv",
+			records: testRecords,
+			comma:   ',',
+			want: `name,price,from,tags,info.brand,info.model
+Zaptec,0.25,2026-10-18T12:00:00Z,a;b,Zaptec,Go
+"quote ""and"", comma",0.0000001,,,,
+`,
+		},
+		{
+			name:    "tsv",
+			records: testRecords[0],
+			comma:   '\t',
+			want:    "name\tprice\tfrom\ttags\tinfo.brand\tinfo.model\nZaptec\t0.25\t2026-10-18T12:00:00Z\ta;b\tZaptec\tGo\n",
+		},
+		{
+			name:    "columns by JSON or field name",
+			records: testRecords,
+			comma:   ',',
+			columns: []string{"Info.Model", "PRICE"},
+			want:    "info.model,price\nGo,0.25\n,0.0000001\n",
+		},
+		{
+			name:    "unknown column",
+			records: testRecords,
+			comma:   ',',
+			columns: []string{"hidden"},
+			wantErr: "unknown column: hidden",
+		},
+		{
+			name: "dataset",
+			records: Dataset{
+				Columns: []string{"month", "usage"},
+				Rows:    [][]interface{}{{"2026-10", 12.5}, {"2026-11", nil}},
+			},
+			comma: ',',
+			want:  "month,usage\n2026-10,12.5\n2026-11,\n",
+		},
+		{
+			name:    "no records",
+			records: []testRecord{},
+			comma:   ',',
+			want:    "name,price,from,tags,info.brand,info.model\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			err := Delimited(&b, tt.records, tt.comma, tt.columns)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		records interface{}
+		want    string
+	}{
+		{
+			name:    "slice",
+			records: testRecords,
+			want: `{"name":"Zaptec","price":0.25,"from":"2026-10-18T12:00:00Z","tags":["a","b"],"info":{"brand":"Zaptec","model":"Go"}}
+{"name":"quote \"and\", comma","price":1e-7,"from":"0001-01-01T00:00:00Z","tags":null,"info":null}
+`,
+		},
+		{
+			name:    "single record",
+			records: &testInfo{Brand: "Tesla", Model: "Model 3"},
+			want:    `{"brand":"Tesla","model":"Model 3"}` + "\n",
+		},
+		{
+			name:    "nil pointer",
+			records: (*testInfo)(nil),
+			want:    "",
+		},
+		{
+			name: "dataset keeps column order",
+			records: &Dataset{
+				Columns: []string{"z", "a"},
+				Rows:    [][]interface{}{{1, "x"}, {2, nil}},
+			},
+			want: `{"z":1,"a":"x"}` + "\n" + `{"z":2,"a":null}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := NDJSON(&b, tt.records); err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
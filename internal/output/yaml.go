@@ -0,0 +1,60 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAML outputs data as YAML
+func YAML(data interface{}) error {
+	return YAMLTo(os.Stdout, data)
+}
+
+// YAMLTo outputs data as YAML to a specific writer. The data is converted
+// through its JSON encoding, so field names and order match the JSON output.
+func YAMLTo(w io.Writer, data interface{}) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	// JSON is valid YAML, and decoding into a node keeps the field order
+	var doc yaml.Node
+	if err := yaml.Unmarshal(encoded, &doc); err != nil {
+		return err
+	}
+	blockStyle(&doc)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// blockStyle clears the JSON flow and quoting styles from a node tree.
+// Strings that would otherwise be read back as another type stay quoted.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!str" && isYAML11Bool(n.Value) {
+		// Keep strings like "yes" and "off" quoted for YAML 1.1 parsers
+		n.Style = yaml.DoubleQuotedStyle
+	}
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
+
+// isYAML11Bool reports whether a YAML 1.1 parser would read a plain value as a boolean
+func isYAML11Bool(value string) bool {
+	switch strings.ToLower(value) {
+	case "y", "yes", "n", "no", "on", "off":
+		return true
+	}
+	return false
+}
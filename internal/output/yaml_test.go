@@ -0,0 +1,65 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestYAMLTo(t *testing.T) {
+	tests := []struct {
+		name string
+		data interface{}
+		want string
+	}{
+		{
+			name: "field order follows JSON",
+			data: testRecords[0],
+			want: `name: Zaptec
+price: 0.25
+from: "2026-10-18T12:00:00Z"
+tags:
+  - a
+  - b
+info:
+  brand: Zaptec
+  model: Go
+`,
+		},
+		{
+			name: "dataset",
+			data: Dataset{Columns: []string{"z", "a"}, Rows: [][]interface{}{{1, nil}}},
+			want: `- z: 1
+  a: null
+`,
+		},
+		{
+			name: "YAML 1.1 booleans stay quoted",
+			data: map[string]interface{}{"answer": "yes", "switch": "off", "enabled": true, "name": "on time"},
+			want: `answer: "yes"
+enabled: true
+name: on time
+switch: "off"
+`,
+		},
+		{
+			name: "numeric strings stay quoted",
+			data: []string{"1234", "0.5", "null"},
+			want: `- "1234"
+- "0.5"
+- "null"
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := YAMLTo(&b, tt.data); err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/pietern/frankie/internal/models"
+)
+
+// SavePrices stores price intervals for a segment (electricity or gas).
+// Use an empty site reference for public market prices.
+func (s *Store) SavePrices(siteRef, segment string, prices []models.Price) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(`INSERT OR REPLACE INTO prices (
+			site_reference, segment, from_time, till_time, resolution,
+			market_price, market_price_tax, sourcing_markup_price, energy_tax_price,
+			market_price_plus, all_in_price, per_unit
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+		if err != nil {
+			return fmt.Errorf("failed to prepare statement: %w", err)
+		}
+		defer stmt.Close()
+
+		for _, p := range prices {
+			_, err := stmt.Exec(
+				siteRef, segment,
+				p.From.UTC().Format(time.RFC3339), p.Till.UTC().Format(time.RFC3339), p.Resolution,
+				p.MarketPrice, p.MarketPriceTax, p.SourcingMarkupPrice, p.EnergyTaxPrice,
+				p.MarketPricePlus, p.AllInPrice, p.PerUnit,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to store price: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// SaveUsage stores the usage items of all categories for a site
+func (s *Store) SaveUsage(siteRef string, usage *models.PeriodUsageAndCosts) error {
+	categories := map[string]*models.EnergyCategory{
+		"electricity": usage.Electricity,
+		"gas":         usage.Gas,
+		"feedin":      usage.FeedIn,
+	}
+
+	return s.inTx(func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(`INSERT OR REPLACE INTO usage (
+			site_reference, segment, date, from_time, till_time, usage, costs, unit
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+		if err != nil {
+			return fmt.Errorf("failed to prepare statement: %w", err)
+		}
+		defer stmt.Close()
+
+		for segment, category := range categories {
+			if category == nil {
+				continue
+			}
+			for _, item := range category.Items {
+				_, err := stmt.Exec(siteRef, segment, item.Date, utcTimestamp(item.From), utcTimestamp(item.Till), item.Usage, item.Costs, item.Unit)
+				if err != nil {
+					return fmt.Errorf("failed to store usage: %w", err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// utcTimestamp converts an RFC 3339 timestamp to UTC, like the times of prices,
+// so ranges compare like with like. Other values are returned as-is.
+func utcTimestamp(value string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// SaveInvoices stores invoices for a site
+func (s *Store) SaveInvoices(siteRef string, invoices []models.Invoice) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(`INSERT OR REPLACE INTO invoices (
+			site_reference, id, invoice_date, start_date, period_description, total_amount
+		) VALUES (?, ?, ?, ?, ?, ?)`)
+		if err != nil {
+			return fmt.Errorf("failed to prepare statement: %w", err)
+		}
+		defer stmt.Close()
+
+		for _, inv := range invoices {
+			_, err := stmt.Exec(siteRef, inv.ID, inv.InvoiceDate, inv.StartDate, inv.PeriodDescription, inv.TotalAmount)
+			if err != nil {
+				return fmt.Errorf("failed to store invoice: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// SaveMonthSummary stores the month summary of a site for a month (YYYY-MM)
+func (s *Store) SaveMonthSummary(siteRef, month string, summary *models.MonthSummary) error {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO month_summaries (
+		site_reference, month, actual_costs, expected_costs_to_date, expected_costs,
+		last_meter_reading_date, completeness, gas_excluded, updated_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		siteRef, month,
+		summary.ActualCostsUntilLastMeterReadingDate,
+		summary.ExpectedCostsUntilLastMeterReadingDate,
+		summary.ExpectedCosts,
+		summary.LastMeterReadingDate,
+		summary.MeterReadingDayCompleteness,
+		summary.GasExcluded,
+		time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store month summary: %w", err)
+	}
+	return nil
+}
+
+// SaveBatterySessions stores trading sessions for a battery
+func (s *Store) SaveBatterySessions(deviceID string, sessions []models.BatterySession) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(`INSERT OR REPLACE INTO battery_sessions (
+			device_id, date, result, cumulative_result, status, trade_index
+		) VALUES (?, ?, ?, ?, ?, ?)`)
+		if err != nil {
+			return fmt.Errorf("failed to prepare statement: %w", err)
+		}
+		defer stmt.Close()
+
+		for _, session := range sessions {
+			_, err := stmt.Exec(deviceID, session.Date, session.Result, session.CumulativeResult, session.Status, session.TradeIndex)
+			if err != nil {
+				return fmt.Errorf("failed to store battery session: %w", err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,38 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/pietern/frankie/internal/models"
+)
+
+func TestSaveUsage(t *testing.T) {
+	s := openTestStore(t)
+	usage := &models.PeriodUsageAndCosts{
+		Electricity: usageOn("2026-10-01", 1, 0.25),
+		FeedIn:      &models.EnergyCategory{Items: []models.UsageItem{{Date: "2026-10-01", From: "2026-10-01T12:00:00Z", Till: "not a time", Usage: 2}}},
+	}
+	if err := s.SaveUsage("1234AB 1", usage); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := s.Query(`SELECT segment, from_time, till_time FROM usage ORDER BY segment`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]interface{}{
+		// Times are stored in UTC, like prices
+		{"electricity", "2026-09-30T22:00:00Z", "2026-09-30T23:00:00Z"},
+		{"feedin", "2026-10-01T12:00:00Z", "not a time"},
+	}
+	if len(result.Rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(result.Rows), len(want))
+	}
+	for i, row := range result.Rows {
+		for j, value := range row {
+			if value != want[i][j] {
+				t.Errorf("row %d column %s = %v, want %v", i, result.Columns[j], value, want[i][j])
+			}
+		}
+	}
+}
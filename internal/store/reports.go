@@ -0,0 +1,208 @@
+package store
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// QueryResult holds the columns and rows returned by an ad-hoc query
+type QueryResult struct {
+	Columns []string
+	Rows    [][]interface{}
+}
+
+// Query runs an ad-hoc SQL query against the history database
+func (s *Store) Query(query string, args ...interface{}) (*QueryResult, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+
+	result := &QueryResult{Columns: columns}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("failed to read row: %w", err)
+		}
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	return result, nil
+}
+
+// Filter restricts reports to a site and date range. Empty fields match everything.
+type Filter struct {
+	SiteReference string
+	From          string
+	To            string
+}
+
+// where builds a WHERE clause for the filter on the given date column
+func (f Filter) where(dateColumn string) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	if f.SiteReference != "" {
+		conds = append(conds, "site_reference = ?")
+		args = append(args, f.SiteReference)
+	}
+	if f.From != "" {
+		conds = append(conds, "substr("+dateColumn+", 1, 10) >= ?")
+		args = append(args, f.From)
+	}
+	if f.To != "" {
+		conds = append(conds, "substr("+dateColumn+", 1, 10) <= ?")
+		args = append(args, f.To)
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// MonthlyCost holds the usage and costs of a site in a single month
+type MonthlyCost struct {
+	Month            string  `json:"month"`
+	SiteReference    string  `json:"siteReference"`
+	ElectricityUsage float64 `json:"electricityUsage"`
+	ElectricityCosts float64 `json:"electricityCosts"`
+	GasUsage         float64 `json:"gasUsage"`
+	GasCosts         float64 `json:"gasCosts"`
+	FeedInUsage      float64 `json:"feedInUsage"`
+	FeedInCosts      float64 `json:"feedInCosts"`
+	TotalCosts       float64 `json:"totalCosts"`
+}
+
+// MonthlyCosts aggregates stored usage and costs per site and month
+func (s *Store) MonthlyCosts(filter Filter) ([]MonthlyCost, error) {
+	where, args := filter.where("date")
+	rows, err := s.db.Query(`SELECT substr(date, 1, 7) AS month, site_reference, segment, SUM(usage), SUM(costs)
+		FROM usage`+where+`
+		GROUP BY month, site_reference, segment
+		ORDER BY month, site_reference`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query monthly costs: %w", err)
+	}
+	defer rows.Close()
+
+	var costs []MonthlyCost
+	index := make(map[string]int)
+	for rows.Next() {
+		var month, siteRef, segment string
+		var usage, cost float64
+		if err := rows.Scan(&month, &siteRef, &segment, &usage, &cost); err != nil {
+			return nil, fmt.Errorf("failed to read row: %w", err)
+		}
+
+		key := month + "/" + siteRef
+		i, ok := index[key]
+		if !ok {
+			i = len(costs)
+			index[key] = i
+			costs = append(costs, MonthlyCost{Month: month, SiteReference: siteRef})
+		}
+
+		c := &costs[i]
+		switch segment {
+		case "electricity":
+			c.ElectricityUsage, c.ElectricityCosts = usage, cost
+		case "gas":
+			c.GasUsage, c.GasCosts = usage, cost
+		case "feedin":
+			c.FeedInUsage, c.FeedInCosts = usage, cost
+		}
+		c.TotalCosts += cost
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query monthly costs: %w", err)
+	}
+
+	return costs, nil
+}
+
+// PriceBucket holds the number of price intervals within a price range
+type PriceBucket struct {
+	From  float64 `json:"from"`
+	Till  float64 `json:"till"`
+	Count int     `json:"count"`
+	Share float64 `json:"share"`
+}
+
+// PriceHistogram groups stored all-in prices of a segment into buckets of
+// the given width. An empty site reference selects public market prices.
+func (s *Store) PriceHistogram(segment string, filter Filter, width float64) ([]PriceBucket, error) {
+	if width <= 0 {
+		return nil, fmt.Errorf("bucket width must be positive")
+	}
+
+	where, args := filter.where("from_time")
+	if where == "" {
+		where = " WHERE "
+	} else {
+		where += " AND "
+	}
+	if filter.SiteReference == "" {
+		where += "site_reference = '' AND "
+	}
+	where += "segment = ?"
+	args = append(args, segment)
+
+	rows, err := s.db.Query(`SELECT CAST(floor(all_in_price / ?) AS INTEGER) AS bucket, COUNT(*)
+		FROM prices`+where+`
+		GROUP BY bucket
+		ORDER BY bucket`, append([]interface{}{width}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price histogram: %w", err)
+	}
+	defer rows.Close()
+
+	var buckets []PriceBucket
+	total := 0
+	for rows.Next() {
+		var bucket int64
+		var count int
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return nil, fmt.Errorf("failed to read row: %w", err)
+		}
+		from := float64(bucket) * width
+		buckets = append(buckets, PriceBucket{
+			From:  roundTo(from, width),
+			Till:  roundTo(from+width, width),
+			Count: count,
+		})
+		total += count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query price histogram: %w", err)
+	}
+
+	for i := range buckets {
+		buckets[i].Share = float64(buckets[i].Count) / float64(total)
+	}
+
+	return buckets, nil
+}
+
+// roundTo removes floating point noise from bucket boundaries
+func roundTo(v, width float64) float64 {
+	scale := math.Pow(10, math.Ceil(-math.Log10(width))+2)
+	return math.Round(v*scale) / scale
+}
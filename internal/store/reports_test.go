@@ -0,0 +1,200 @@
+package store
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pietern/frankie/internal/models"
+)
+
+// openTestStore opens a new history database in a temporary directory
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// usageOn returns a category with a single usage item on a date
+func usageOn(date string, usage, costs float64) *models.EnergyCategory {
+	return &models.EnergyCategory{Items: []models.UsageItem{{
+		Date:  date,
+		From:  date + "T00:00:00+02:00",
+		Till:  date + "T01:00:00+02:00",
+		Usage: usage,
+		Costs: costs,
+	}}}
+}
+
+func TestMonthlyCosts(t *testing.T) {
+	s := openTestStore(t)
+	saves := []struct {
+		site  string
+		usage *models.PeriodUsageAndCosts
+	}{
+		{"1234AB 1", &models.PeriodUsageAndCosts{
+			Electricity: usageOn("2026-09-30", 10, 2.5),
+			Gas:         usageOn("2026-09-30", 3, 4.5),
+			FeedIn:      usageOn("2026-09-30", 2, -0.5),
+		}},
+		{"1234AB 1", &models.PeriodUsageAndCosts{Electricity: usageOn("2026-10-01", 8, 2)}},
+		{"1234AB 1", &models.PeriodUsageAndCosts{Electricity: usageOn("2026-10-02", 4, 1)}},
+		{"4321ZZ 5", &models.PeriodUsageAndCosts{Gas: usageOn("2026-10-01", 1, 1.5)}},
+	}
+	for _, save := range saves {
+		if err := s.SaveUsage(save.site, save.usage); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	september := MonthlyCost{
+		Month: "2026-09", SiteReference: "1234AB 1",
+		ElectricityUsage: 10, ElectricityCosts: 2.5,
+		GasUsage: 3, GasCosts: 4.5,
+		FeedInUsage: 2, FeedInCosts: -0.5,
+		TotalCosts: 6.5,
+	}
+	october := MonthlyCost{Month: "2026-10", SiteReference: "1234AB 1", ElectricityUsage: 12, ElectricityCosts: 3, TotalCosts: 3}
+	otherSite := MonthlyCost{Month: "2026-10", SiteReference: "4321ZZ 5", GasUsage: 1, GasCosts: 1.5, TotalCosts: 1.5}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []MonthlyCost
+	}{
+		{name: "all", want: []MonthlyCost{september, october, otherSite}},
+		{name: "site", filter: Filter{SiteReference: "4321ZZ 5"}, want: []MonthlyCost{otherSite}},
+		{name: "from", filter: Filter{From: "2026-10-01"}, want: []MonthlyCost{october, otherSite}},
+		{
+			name:   "to",
+			filter: Filter{SiteReference: "1234AB 1", To: "2026-10-01"},
+			want: []MonthlyCost{september, {
+				Month: "2026-10", SiteReference: "1234AB 1", ElectricityUsage: 8, ElectricityCosts: 2, TotalCosts: 2,
+			}},
+		},
+		{name: "nothing", filter: Filter{From: "2026-11-01"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.MonthlyCosts(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MonthlyCosts(%+v) =\n%+v\nwant\n%+v", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPriceHistogram(t *testing.T) {
+	s := openTestStore(t)
+
+	// prices returns hourly prices from midnight UTC on a date
+	prices := func(date string, allIn ...float64) []models.Price {
+		start, _ := time.Parse("2006-01-02", date)
+		var result []models.Price
+		for i, price := range allIn {
+			from := start.Add(time.Duration(i) * time.Hour)
+			result = append(result, models.Price{From: from, Till: from.Add(time.Hour), AllInPrice: price})
+		}
+		return result
+	}
+	saves := []struct {
+		site    string
+		segment string
+		prices  []models.Price
+	}{
+		{"", "electricity", prices("2026-10-01", 0.21, 0.24, 0.26, 0.31)},
+		{"", "electricity", prices("2026-10-02", 0.22, -0.01)},
+		{"", "gas", prices("2026-10-01", 1.20)},
+		{"1234AB 1", "electricity", prices("2026-10-01", 0.50)},
+	}
+	for _, save := range saves {
+		if err := s.SavePrices(save.site, save.segment, save.prices); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		segment string
+		filter  Filter
+		width   float64
+		want    []PriceBucket
+		wantErr bool
+	}{
+		{
+			name:    "public prices",
+			segment: "electricity",
+			width:   0.05,
+			want: []PriceBucket{
+				{From: -0.05, Till: 0, Count: 1},
+				{From: 0.2, Till: 0.25, Count: 3},
+				{From: 0.25, Till: 0.3, Count: 1},
+				{From: 0.3, Till: 0.35, Count: 1},
+			},
+		},
+		{
+			name:    "date",
+			segment: "electricity",
+			filter:  Filter{From: "2026-10-02"},
+			width:   0.1,
+			want: []PriceBucket{
+				{From: -0.1, Till: 0, Count: 1},
+				{From: 0.2, Till: 0.3, Count: 1},
+			},
+		},
+		{
+			name:    "site",
+			segment: "electricity",
+			filter:  Filter{SiteReference: "1234AB 1"},
+			width:   0.1,
+			want:    []PriceBucket{{From: 0.5, Till: 0.6, Count: 1}},
+		},
+		{
+			name:    "gas",
+			segment: "gas",
+			width:   0.5,
+			want:    []PriceBucket{{From: 1, Till: 1.5, Count: 1}},
+		},
+		{name: "no prices", segment: "electricity", filter: Filter{To: "2026-09-30"}, width: 0.1},
+		{name: "zero width", segment: "electricity", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.PriceHistogram(tt.segment, tt.filter, tt.width)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("PriceHistogram = %+v, want %+v", got, tt.want)
+			}
+			total := 0
+			for _, bucket := range tt.want {
+				total += bucket.Count
+			}
+			for i, bucket := range got {
+				want := tt.want[i]
+				want.Share = float64(want.Count) / float64(total)
+				if bucket.From != want.From || bucket.Till != want.Till || bucket.Count != want.Count || math.Abs(bucket.Share-want.Share) > 1e-9 {
+					t.Errorf("bucket %d = %+v, want %+v", i, bucket, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/pietern/frankie/internal/filelock"
+)
+
+// schema creates the history tables. Prices with an empty site reference are
+// public market prices; all other rows are specific to a site.
+const schema = `
+CREATE TABLE IF NOT EXISTS prices (
+    site_reference        TEXT NOT NULL DEFAULT '',
+    segment               TEXT NOT NULL,
+    from_time             TEXT NOT NULL,
+    till_time             TEXT NOT NULL,
+    resolution            TEXT NOT NULL DEFAULT '',
+    market_price          REAL NOT NULL,
+    market_price_tax      REAL NOT NULL,
+    sourcing_markup_price REAL NOT NULL,
+    energy_tax_price      REAL NOT NULL,
+    market_price_plus     REAL NOT NULL,
+    all_in_price          REAL NOT NULL,
+    per_unit              TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (site_reference, segment, resolution, from_time)
+);
+
+CREATE TABLE IF NOT EXISTS usage (
+    site_reference TEXT NOT NULL,
+    segment        TEXT NOT NULL,
+    date           TEXT NOT NULL,
+    from_time      TEXT NOT NULL,
+    till_time      TEXT NOT NULL,
+    usage          REAL NOT NULL,
+    costs          REAL NOT NULL,
+    unit           TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (site_reference, segment, from_time)
+);
+
+CREATE TABLE IF NOT EXISTS invoices (
+    site_reference     TEXT NOT NULL,
+    id                 TEXT NOT NULL,
+    invoice_date       TEXT NOT NULL DEFAULT '',
+    start_date         TEXT NOT NULL DEFAULT '',
+    period_description TEXT NOT NULL DEFAULT '',
+    total_amount       REAL NOT NULL,
+    PRIMARY KEY (site_reference, id)
+);
+
+CREATE TABLE IF NOT EXISTS month_summaries (
+    site_reference          TEXT NOT NULL,
+    month                   TEXT NOT NULL,
+    actual_costs            REAL NOT NULL,
+    expected_costs_to_date  REAL NOT NULL,
+    expected_costs          REAL NOT NULL,
+    last_meter_reading_date TEXT NOT NULL DEFAULT '',
+    completeness            REAL NOT NULL,
+    gas_excluded            INTEGER NOT NULL,
+    updated_at              TEXT NOT NULL,
+    PRIMARY KEY (site_reference, month)
+);
+
+CREATE TABLE IF NOT EXISTS battery_sessions (
+    device_id         TEXT NOT NULL,
+    date              TEXT NOT NULL,
+    result            REAL NOT NULL,
+    cumulative_result REAL NOT NULL,
+    status            TEXT NOT NULL DEFAULT '',
+    trade_index       REAL NOT NULL,
+    PRIMARY KEY (device_id, date)
+);
+
+CREATE TABLE IF NOT EXISTS sync_state (
+    dataset    TEXT NOT NULL,
+    key        TEXT NOT NULL,
+    last_date  TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    PRIMARY KEY (dataset, key)
+);
+`
+
+// migrations upgrade databases created by earlier versions, in order. The
+// user_version pragma records how many have been applied.
+var migrations = []string{}
+
+// ErrNoDatabase is returned when the history database has not been created yet
+var ErrNoDatabase = errors.New("no local history database")
+
+// Store is the local SQLite history database
+type Store struct {
+	db *sql.DB
+}
+
+// fileURI returns the URI of a database file with query parameters. Unlike a
+// plain file name, it may contain characters such as ? and #.
+func fileURI(path, query string) string {
+	uri := url.URL{Scheme: "file", Path: path, RawQuery: query}
+	return uri.String()
+}
+
+// Open opens (and if needed creates) the history database at path
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", fileURI(path, ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+
+	st := &Store{db: db}
+	if err := st.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return st, nil
+}
+
+// Upgrade applies pending migrations to an existing database, so it can be
+// read with OpenReadOnly
+func Upgrade(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	s, err := Open(path)
+	if err != nil {
+		return err
+	}
+	return s.Close()
+}
+
+// migrate applies the migrations that have not been applied yet
+func (s *Store) migrate() error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read database version: %w", err)
+	}
+	if version >= len(migrations) {
+		return nil
+	}
+	return s.inTx(func(tx *sql.Tx) error {
+		for i := version; i < len(migrations); i++ {
+			if _, err := tx.Exec(migrations[i]); err != nil {
+				return fmt.Errorf("failed to migrate database: %w", err)
+			}
+		}
+		// Pragmas cannot take parameters
+		_, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", len(migrations)))
+		return err
+	})
+}
+
+// OpenReadOnly opens an existing history database without allowing changes
+func OpenReadOnly(path string) (*Store, error) {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoDatabase
+		}
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", fileURI(path, "mode=ro"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// moveLockTimeout is how long Move waits for another process moving the database
+const moveLockTimeout = 10 * time.Second
+
+// Move moves the history database at oldPath to newPath, if it exists at
+// oldPath only. It reports whether the database was moved.
+//
+// Concurrent moves are serialized with a lock file next to newPath. The
+// database is switched to a rollback journal and locked exclusively first,
+// which fails if another process is using it, so a single file is moved
+// and no write-ahead log can be left behind.
+func Move(oldPath, newPath string) (bool, error) {
+	if _, err := os.Stat(oldPath); err != nil {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(newPath), 0700); err != nil {
+		return false, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	lock, err := filelock.Acquire(newPath+".lock", moveLockTimeout)
+	if err != nil {
+		return false, err
+	}
+	defer lock.Unlock()
+
+	// Another process may have moved the database while waiting for the lock
+	if _, err := os.Stat(oldPath); err != nil {
+		return false, nil
+	}
+	if _, err := os.Stat(newPath); err == nil {
+		return false, fmt.Errorf("history database exists in both %s and %s; remove one of them", oldPath, newPath)
+	}
+
+	if err := checkpoint(oldPath); err != nil {
+		return false, fmt.Errorf("failed to move history database from %s: %w (stop other frankie processes and try again)", oldPath, err)
+	}
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		if _, err := os.Stat(oldPath + suffix); err == nil {
+			return false, fmt.Errorf("failed to move history database from %s: %s still exists", oldPath, oldPath+suffix)
+		}
+	}
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return false, fmt.Errorf("failed to move history database to %s: %w", newPath, err)
+	}
+	return true, nil
+}
+
+// checkpoint makes the database at path self-contained: it recovers any
+// interrupted transaction, moves the write-ahead log into the database and
+// checks that no other connection is using it
+func checkpoint(path string) error {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", fileURI(path, ""))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var mode string
+	if err := conn.QueryRowContext(ctx, "PRAGMA journal_mode=DELETE").Scan(&mode); err != nil {
+		return err
+	}
+	if mode != "delete" {
+		return fmt.Errorf("database is in use")
+	}
+	if _, err := conn.ExecContext(ctx, "BEGIN EXCLUSIVE"); err != nil {
+		return err
+	}
+	_, err = conn.ExecContext(ctx, "ROLLBACK")
+	return err
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// DB returns the underlying database handle
+func (s *Store) DB() *sql.DB {
+	return s.db
+}
+
+// LastSynced returns the last synced date for a dataset and key,
+// or an empty string if it has never been synced.
+func (s *Store) LastSynced(dataset, key string) (string, error) {
+	var lastDate string
+	err := s.db.QueryRow(
+		`SELECT last_date FROM sync_state WHERE dataset = ? AND key = ?`,
+		dataset, key,
+	).Scan(&lastDate)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read sync state: %w", err)
+	}
+	return lastDate, nil
+}
+
+// SetLastSynced records the last synced date for a dataset and key
+func (s *Store) SetLastSynced(dataset, key, date string) error {
+	_, err := s.db.Exec(
+		`INSERT OR REPLACE INTO sync_state (dataset, key, last_date, updated_at) VALUES (?, ?, ?, ?)`,
+		dataset, key, date, time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write sync state: %w", err)
+	}
+	return nil
+}
+
+// inTx runs fn within a transaction
+func (s *Store) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenAndUpgrade(t *testing.T) {
+	tests := []struct {
+		name       string
+		migrations []string
+		// create creates the database before the migrations are added
+		create      bool
+		wantVersion int
+		wantRows    int
+	}{
+		{name: "no migrations", create: true},
+		{
+			name:        "new database",
+			migrations:  []string{`INSERT INTO sync_state VALUES ('prices', 'NL/PT60M', '2026-10-01', '')`},
+			wantVersion: 1,
+			wantRows:    1,
+		},
+		{
+			name: "pending migrations",
+			migrations: []string{
+				`INSERT INTO sync_state VALUES ('prices', 'NL/PT60M', '2026-10-01', '')`,
+				`INSERT INTO sync_state VALUES ('prices', 'NL/PT15M', '2026-10-01', '')`,
+			},
+			create:      true,
+			wantVersion: 2,
+			wantRows:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "profile", "history.db")
+			if err := Upgrade(path); err != nil {
+				t.Fatalf("Upgrade of a missing database: %v", err)
+			}
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Fatalf("Upgrade created %s", path)
+			}
+
+			if tt.create {
+				s, err := Open(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				s.Close()
+			}
+
+			saved := migrations
+			migrations = tt.migrations
+			t.Cleanup(func() { migrations = saved })
+
+			if !tt.create {
+				s, err := Open(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				s.Close()
+			}
+
+			// Upgrading again applies each migration once
+			for i := 0; i < 2; i++ {
+				if err := Upgrade(path); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			s, err := OpenReadOnly(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer s.Close()
+
+			var version, rows int
+			if err := s.DB().QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.DB().QueryRow("SELECT COUNT(*) FROM sync_state").Scan(&rows); err != nil {
+				t.Fatal(err)
+			}
+			if version != tt.wantVersion || rows != tt.wantRows {
+				t.Errorf("version %d with %d rows, want %d with %d", version, rows, tt.wantVersion, tt.wantRows)
+			}
+		})
+	}
+}
+
+func TestOpenReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	if _, err := OpenReadOnly(path); !errors.Is(err, ErrNoDatabase) {
+		t.Fatalf("OpenReadOnly of a missing database = %v, want ErrNoDatabase", err)
+	}
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	s, err = OpenReadOnly(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if err := s.SetLastSynced("prices", "NL/PT60M", "2026-10-01"); err == nil {
+		t.Fatal("SetLastSynced succeeded on a read-only database")
+	}
+}
+
+func TestSyncState(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	steps := []struct {
+		dataset string
+		key     string
+		// set is stored first, if not empty
+		set  string
+		want string
+	}{
+		{dataset: "prices", key: "NL/PT60M", want: ""},
+		{dataset: "prices", key: "NL/PT60M", set: "2026-10-01", want: "2026-10-01"},
+		{dataset: "prices", key: "NL/PT15M", want: ""},
+		{dataset: "usage", key: "NL/PT60M", want: ""},
+		{dataset: "prices", key: "NL/PT60M", set: "2026-10-05", want: "2026-10-05"},
+		{dataset: "prices", key: "NL/PT15M", set: "2026-09-30", want: "2026-09-30"},
+		{dataset: "prices", key: "NL/PT60M", want: "2026-10-05"},
+	}
+
+	for _, step := range steps {
+		if step.set != "" {
+			if err := s.SetLastSynced(step.dataset, step.key, step.set); err != nil {
+				t.Fatal(err)
+			}
+		}
+		got, err := s.LastSynced(step.dataset, step.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != step.want {
+			t.Errorf("LastSynced(%s, %s) = %q, want %q", step.dataset, step.key, got, step.want)
+		}
+	}
+}
+
+func TestMove(t *testing.T) {
+	tests := []struct {
+		name      string
+		oldExists bool
+		newExists bool
+		wantMoved bool
+		wantErr   bool
+	}{
+		{name: "moved", oldExists: true, wantMoved: true},
+		{name: "nothing to move", newExists: true},
+		{name: "neither exists"},
+		{name: "both exist", oldExists: true, newExists: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			oldPath := filepath.Join(dir, "config", "history.db")
+			newPath := filepath.Join(dir, "data", "history.db")
+			for path, exists := range map[string]bool{oldPath: tt.oldExists, newPath: tt.newExists} {
+				if !exists {
+					continue
+				}
+				s, err := Open(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := s.SetLastSynced("prices", "NL/PT60M", "2026-10-01"); err != nil {
+					t.Fatal(err)
+				}
+				s.Close()
+			}
+
+			moved, err := Move(oldPath, newPath)
+			if (err != nil) != tt.wantErr || moved != tt.wantMoved {
+				t.Fatalf("Move = %v, %v, want moved %v and error %v", moved, err, tt.wantMoved, tt.wantErr)
+			}
+			if !moved {
+				return
+			}
+
+			if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+				t.Errorf("%s still exists", oldPath)
+			}
+			s, err := OpenReadOnly(newPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer s.Close()
+			if got, err := s.LastSynced("prices", "NL/PT60M"); err != nil || got != "2026-10-01" {
+				t.Errorf("LastSynced after moving = %q, %v", got, err)
+			}
+		})
+	}
+}